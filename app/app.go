@@ -2,11 +2,15 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"HyPrism/internal/auth"
 	"HyPrism/internal/config"
@@ -14,15 +18,30 @@ import (
 	"HyPrism/internal/game"
 	"HyPrism/internal/mods"
 	"HyPrism/internal/news"
+	"HyPrism/internal/server"
+	"HyPrism/internal/util"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx         context.Context
-	cfg         *config.Config
-	newsService *news.NewsService
+	ctx          context.Context
+	cfg          *config.Config
+	fileCfg      *config.Config    // config as read from disk, before overrides
+	overrides    *config.Overrides // values set through environment variables and flags
+	overrideErr  error             // why the overrides were ignored, shown once the window is up
+	newsService  *news.NewsService
+	gameSessions *game.SessionManager
+	servers      *server.Manager
+	sessions     *auth.SessionService
+
+	loginMu     sync.Mutex
+	loginInput  *auth.CodeInput    // pasted codes of the running login
+	loginCancel context.CancelFunc // cancels the running login
+
+	integrityMu   sync.Mutex
+	launchedBuild atomic.Pointer[game.GameBuild] // build of the running game, recorded after a clean exit
 }
 
 // ProgressUpdate represents download/install progress
@@ -38,14 +57,43 @@ type ProgressUpdate struct {
 
 // NewApp creates a new App instance
 func NewApp() *App {
-	cfg, _ := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		// The damaged file was moved aside, so saving the defaults does not overwrite it
+		fmt.Printf("Warning: failed to load config, using defaults: %v\n", err)
+	}
 	if cfg == nil {
 		cfg = config.Default()
 	}
-	return &App{
+
+	// HYPRISM_* environment variables and --set flags win over the config file
+	fileCfg := cfg.Clone()
+	overrides, err := config.ParseOverrides(os.Environ(), os.Args[1:])
+	if err == nil {
+		err = overrides.Apply(cfg)
+	}
+	overrideErr := err
+	if err != nil {
+		fmt.Printf("Warning: ignoring config overrides: %v\n", err)
+		overrides, _ = config.ParseOverrides(nil, nil)
+		cfg = fileCfg.Clone()
+	}
+	for _, o := range overrides.List() {
+		fmt.Printf("Config override: %s = %q (from %s)\n", o.Key, o.Value, o.Source)
+	}
+	env.SetGameInstallPath(cfg.GameInstallPath)
+
+	a := &App{
 		cfg:         cfg,
+		fileCfg:     fileCfg,
+		overrides:   overrides,
+		overrideErr: overrideErr,
 		newsService: news.NewNewsService(),
 	}
+	a.gameSessions = game.NewSessionManager(a.onGameEvent)
+	a.servers = server.NewManager(a.emitGameEvent)
+	a.sessions = auth.NewSessionService(a.emitGameEvent)
+	return a
 }
 
 // Startup is called when the app starts
@@ -58,11 +106,25 @@ func (a *App) Startup(ctx context.Context) {
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 
 
+	// Warn about damaged settings, accounts or mod lists that were restored from backups
+	util.SetRecoveryListener(func(notice util.RecoveryNotice) {
+		a.emitGameEvent("storage-recovered", notice)
+	})
+
 	// Initialize environment
 	if err := env.CreateFolders(); err != nil {
 		fmt.Printf("Warning: Failed to create folders: %v\n", err)
 	}
 
+	// Find the official installation on first run
+	a.discoverGameInstall()
+
+	// Take scheduled world backups of local servers
+	a.servers.StartBackupScheduler()
+
+	// Refresh login tokens before they expire
+	a.sessions.Start()
+
 	// Check for launcher updates in background
 	go func() {
 		fmt.Println("Starting background update check...")
@@ -73,6 +135,9 @@ func (a *App) Startup(ctx context.Context) {
 // Shutdown is called when the app closes
 func (a *App) Shutdown(ctx context.Context) {
 	fmt.Println("HyPrism shutting down...")
+	a.sessions.Stop()
+	a.servers.StopBackupScheduler()
+	a.servers.StopAll(server.DefaultStopTimeout)
 }
 
 // SelectInstanceDirectory opens a folder picker dialog and saves the selected directory
@@ -121,20 +186,63 @@ func (a *App) SelectGameInstallDirectory() (string, error) {
 		return "", nil // User cancelled
 	}
 	
+	if err := a.setGameInstallPath(selectedDir); err != nil {
+		return "", err
+	}
+
+	return selectedDir, nil
+}
+
+// setGameInstallPath validates an official installation and saves it to config
+func (a *App) setGameInstallPath(path string) error {
 	// Validate that this looks like a Hytale installation
-	gameDir := filepath.Join(selectedDir, "install", "release", "package", "game", "latest")
-	if _, err := os.Stat(gameDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("invalid Hytale installation: game directory not found at %s", gameDir)
+	if !game.IsValidInstall(path) {
+		return fmt.Errorf("invalid Hytale installation: no game builds found under %s", filepath.Join(path, "install"))
 	}
-	
+
 	// Save to config
-	a.cfg.GameInstallPath = selectedDir
-	if err := config.Save(a.cfg); err != nil {
-		return "", fmt.Errorf("failed to save config: %w", err)
+	a.cfg.GameInstallPath = path
+	env.SetGameInstallPath(path)
+	if err := a.saveConfig(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
 	}
-	
-	fmt.Printf("Game install directory set to: %s\n", selectedDir)
-	return selectedDir, nil
+
+	fmt.Printf("Game install directory set to: %s\n", path)
+	return nil
+}
+
+// DiscoverGameInstalls probes the official launcher's default install locations
+// and returns every installation found along with its patchlines
+func (a *App) DiscoverGameInstalls() []game.InstallCandidate {
+	return game.DiscoverInstalls()
+}
+
+// UseGameInstall selects a discovered (or any other) installation directory
+func (a *App) UseGameInstall(path string) error {
+	return a.setGameInstallPath(path)
+}
+
+// discoverGameInstall configures the first discovered installation when none is set
+func (a *App) discoverGameInstall() {
+	if a.cfg.GameInstallPath != "" {
+		return
+	}
+
+	candidates := game.DiscoverInstalls()
+	if len(candidates) == 0 {
+		fmt.Println("No Hytale installation found in default locations")
+		return
+	}
+
+	if err := a.setGameInstallPath(candidates[0].Path); err != nil {
+		fmt.Printf("Warning: failed to use discovered installation: %v\n", err)
+		return
+	}
+
+	if a.ctx == nil {
+		return
+	}
+	wailsRuntime.EventsEmit(a.ctx, "game-install-discovered", candidates)
 }
 
 // progressCallback sends progress updates to frontend
@@ -170,6 +278,11 @@ func (a *App) GetLauncherVersion() string {
 	return AppVersion
 }
 
+// IsPortableMode returns true if all launcher data is kept next to the executable
+func (a *App) IsPortableMode() bool {
+	return env.IsPortable()
+}
+
 // Launch launches the game using the official Hytale installation
 func (a *App) Launch(playerName string) error {
 	// Validate nickname
@@ -192,10 +305,30 @@ func (a *App) Launch(playerName string) error {
 		return err
 	}
 
+	// Play as the profile with this nickname
+	if err := a.usePlayerProfile(playerName); err != nil {
+		a.emitError(err)
+		return err
+	}
+
+	// Check the game files against their manifest before starting the client
+	build := a.verifyBeforeLaunch(a.progressCallback)
+
+	// The manifest is recorded once this session exits cleanly. The build is stored before
+	// the session starts, because a game that exits at once is handled before LaunchInstance returns.
+	a.launchedBuild.Store(build)
+
 	// Launch the game
 	a.progressCallback("launch", 100, "Launching game...", "", "", 0, 0)
 
-	if err := game.LaunchInstance(a.cfg.GameInstallPath); err != nil {
+	launch, err := a.prepareLaunch()
+	if err != nil {
+		a.launchedBuild.CompareAndSwap(build, nil)
+		a.emitError(err)
+		return err
+	}
+	if err := game.LaunchInstance(a.gameSessions, launch); err != nil {
+		a.launchedBuild.CompareAndSwap(build, nil)
 		wrappedErr := GameError("Failed to launch game", err)
 		a.emitError(wrappedErr)
 		return wrappedErr
@@ -206,7 +339,7 @@ func (a *App) Launch(playerName string) error {
 
 // GetLogs returns launcher logs
 func (a *App) GetLogs() (string, error) {
-	logPath := filepath.Join(env.GetDefaultAppDir(), "logs", "launcher.log")
+	logPath := filepath.Join(env.GetLogsDir(), "launcher.log")
 	data, err := os.ReadFile(logPath)
 	if err != nil {
 		return "", err
@@ -388,17 +521,54 @@ func (a *App) QuickLaunch() error {
 
 // ExitGame terminates the running game process
 func (a *App) ExitGame() error {
-	return game.KillGame()
+	return a.gameSessions.Kill()
 }
 
 // IsGameRunning returns whether the game is currently running
 func (a *App) IsGameRunning() bool {
-	return game.IsGameRunning()
+	return a.gameSessions.IsRunning()
+}
+
+// GetGameSession returns the running game session, or the last one if the game has exited
+func (a *App) GetGameSession() *game.SessionInfo {
+	info, ok := a.gameSessions.Current()
+	if !ok {
+		return nil
+	}
+	return &info
+}
+
+// emitGameEvent forwards game lifecycle events and live output to the frontend
+func (a *App) emitGameEvent(event string, data interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	wailsRuntime.EventsEmit(a.ctx, event, data)
+}
+
+// ListGameLogs returns the stored game session logs, newest first
+func (a *App) ListGameLogs() ([]game.GameLogInfo, error) {
+	logs, err := game.ListGameLogs()
+	if err != nil {
+		return nil, err
+	}
+
+	if current, ok := a.gameSessions.Current(); ok && current.Running {
+		for i := range logs {
+			if logs[i].SessionID == current.ID {
+				logs[i].Running = true
+			}
+		}
+	}
+
+	return logs, nil
 }
 
-// GetGameLogs returns the game log content
-func (a *App) GetGameLogs() (string, error) {
-	return game.GetGameLogs()
+// GetGameLogs returns the log content of a game session.
+// An empty sessionID returns the newest session's log.
+// While the game runs, new output is streamed through "game-log" events.
+func (a *App) GetGameLogs(sessionID string) (string, error) {
+	return game.ReadGameLog(sessionID)
 }
 
 
@@ -406,6 +576,46 @@ func (a *App) GetGameLogs() (string, error) {
 
 // LoginWithHytaleAccount initiates the Hytale account login flow
 func (a *App) LoginWithHytaleAccount() error {
+	return a.login(false)
+}
+
+// LoginWithHytaleAccountManual starts a login that does not open a browser. The authorization
+// URL is sent to the frontend in an auth-url event so the user can open it on any device and
+// paste the address they end up on, or the code, into SubmitLoginCode.
+func (a *App) LoginWithHytaleAccountManual() error {
+	return a.login(true)
+}
+
+// SubmitLoginCode passes a pasted redirect URL or authorization code to the running login
+func (a *App) SubmitLoginCode(input string) error {
+	a.loginMu.Lock()
+	codeInput := a.loginInput
+	a.loginMu.Unlock()
+
+	if codeInput == nil {
+		return ValidationError("No login is in progress")
+	}
+	if err := codeInput.Submit(a.ctx, input); err != nil {
+		return ValidationError(err.Error())
+	}
+	return nil
+}
+
+// CancelLogin stops the running login
+func (a *App) CancelLogin() error {
+	a.loginMu.Lock()
+	cancel := a.loginCancel
+	a.loginMu.Unlock()
+
+	if cancel == nil {
+		return ValidationError("No login is in progress")
+	}
+	cancel()
+	return nil
+}
+
+// login runs the login flow. Codes can always be pasted; in manual mode no browser is opened.
+func (a *App) login(manual bool) error {
 	fmt.Println("Starting Hytale account login...")
 	
 	// Emit progress updates to frontend
@@ -414,12 +624,47 @@ func (a *App) LoginWithHytaleAccount() error {
 		fmt.Printf("[AUTH] %s\n", message)
 	}
 	
-	// Browser opener function
+	// Browser opener function; if no browser can be opened the URL is shown instead
 	openBrowser := func(url string) error {
-		return auth.OpenBrowser(url)
-	}
+		if !manual {
+			err := auth.OpenBrowser(url)
+			if err == nil {
+				return nil
+			}
+			fmt.Printf("Warning: failed to open browser: %v\n", err)
+		}
+		wailsRuntime.EventsEmit(a.ctx, "auth-url", url)
+		return nil
+	}
+
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	codeInput := auth.NewCodeInput()
+	a.loginMu.Lock()
+	if a.loginInput != nil {
+		a.loginMu.Unlock()
+		return ValidationError("A login is already in progress")
+	}
+	a.loginInput = codeInput
+	a.loginCancel = cancel
+	a.loginMu.Unlock()
+	defer func() {
+		a.loginMu.Lock()
+		a.loginInput = nil
+		a.loginCancel = nil
+		a.loginMu.Unlock()
+	}()
 	
-	session, err := auth.LoginWithAuthCodeFlow(a.ctx, progressCallback, openBrowser)
+	session, err := auth.LoginWithAuthCodeFlow(ctx, progressCallback, openBrowser, codeInput)
+	if errors.Is(err, auth.ErrLoginCancelled) {
+		wailsRuntime.EventsEmit(a.ctx, "auth-cancelled", nil)
+		fmt.Println("Login cancelled")
+	}
 	if err != nil {
 		return fmt.Errorf("login failed: %w", err)
 	}
@@ -428,30 +673,204 @@ func (a *App) LoginWithHytaleAccount() error {
 	if err := auth.SaveSession(session); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
+	a.sessions.Invalidate()
 	
-	wailsRuntime.EventsEmit(a.ctx, "auth-success", map[string]string{
+	wailsRuntime.EventsEmit(a.ctx, "auth-success", map[string]interface{}{
 		"username": session.Username,
 		"uuid":     session.UUID,
+		"profiles": session.Profiles,
 	})
 	
 	fmt.Printf("Successfully logged in as %s (UUID: %s)\n", session.Username, session.UUID)
 	return nil
 }
 
-// LogoutHytaleAccount logs out the current Hytale account
-func (a *App) LogoutHytaleAccount() error {
-	if err := auth.ClearSession(); err != nil {
+// LogoutHytaleAccount signs out the current Hytale account: its tokens are revoked and every
+// local trace of it is removed. The report lists which steps succeeded.
+func (a *App) LogoutHytaleAccount() (*auth.LogoutReport, error) {
+	key := activeAccountKey()
+	if key == "" {
+		return nil, ValidationError("Not logged in")
+	}
+	return a.signOut(key, "auth-logout")
+}
+
+// ListAccounts returns every signed-in Hytale account
+func (a *App) ListAccounts() ([]auth.AccountInfo, error) {
+	return auth.ListAccounts()
+}
+
+// SwitchAccount makes a signed-in account the one used for launches
+func (a *App) SwitchAccount(key string) error {
+	if a.gameSessions.IsRunning() {
+		return ValidationError("Close the game before switching accounts")
+	}
+
+	if err := auth.SwitchAccount(key); err != nil {
 		return err
 	}
-	
-	wailsRuntime.EventsEmit(a.ctx, "auth-logout", nil)
-	fmt.Println("Logged out successfully")
+
+	a.emitAccountChanged("auth-account-switched")
 	return nil
 }
 
+// RemoveAccount signs out a single account. If it was active, another account becomes active.
+func (a *App) RemoveAccount(key string) (*auth.LogoutReport, error) {
+	return a.signOut(key, "auth-account-removed")
+}
+
+// signOut revokes the tokens of an account, removes it and scrubs it from the stored logs
+func (a *App) signOut(key, event string) (*auth.LogoutReport, error) {
+	if a.gameSessions.IsRunning() {
+		return nil, ValidationError("Close the game before signing out")
+	}
+
+	wasActive := key == activeAccountKey()
+	report, err := auth.SignOut(key)
+	if err != nil {
+		return nil, err
+	}
+	a.sessions.Invalidate()
+
+	scrubbed, err := game.ScrubLogs([]string{report.UUID, report.AccountOwnerID}, report.Username, server.CrashHistoryFiles()...)
+	if err == nil && scrubbed > 0 {
+		fmt.Printf("Removed account references from %d log files\n", scrubbed)
+	}
+	report.Record("Remove account from logs", err)
+
+	for _, step := range report.Steps {
+		if step.OK {
+			fmt.Printf("[LOGOUT] %s: done\n", step.Name)
+		} else {
+			fmt.Printf("[LOGOUT] %s: failed: %s\n", step.Name, step.Error)
+		}
+	}
+	fmt.Printf("Signed out %s\n", report.Username)
+
+	a.emitGameEvent(event, report)
+
+	// Another signed-in account takes over
+	if wasActive && activeAccountKey() != "" {
+		a.emitAccountChanged("auth-account-switched")
+	}
+	return report, nil
+}
+
+// activeAccountKey returns the key of the active account, or "" if no account is signed in.
+// It does not need the account's tokens, so it works while they are locked.
+func activeAccountKey() string {
+	accounts, err := auth.ListAccounts()
+	if err != nil {
+		return ""
+	}
+	for _, account := range accounts {
+		if account.Active {
+			return account.Key
+		}
+	}
+	return ""
+}
+
+// GetProfiles returns the game profiles of the active account
+func (a *App) GetProfiles() ([]auth.Profile, error) {
+	profiles, err := auth.RefreshProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("not logged in: %w", err)
+	}
+	return profiles, nil
+}
+
+// SelectProfile chooses which game profile of the active account to play as.
+// The choice is remembered for the account.
+func (a *App) SelectProfile(uuid string) error {
+	if a.gameSessions.IsRunning() {
+		return ValidationError("Close the game before switching profiles")
+	}
+
+	if _, err := auth.SelectProfile(uuid); err != nil {
+		return err
+	}
+
+	a.emitAccountChanged("auth-account-switched")
+	return nil
+}
+
+// GetTokenStorage reports where login tokens are stored and whether they need a passphrase
+func (a *App) GetTokenStorage() auth.TokenStorageInfo {
+	return auth.GetTokenStorage()
+}
+
+// SetTokenPassphrase protects the encrypted token file with a passphrase that has to be
+// entered once per launcher start. An empty passphrase binds the file to this machine instead.
+func (a *App) SetTokenPassphrase(passphrase string) error {
+	if err := auth.SetTokenPassphrase(passphrase); err != nil {
+		return fmt.Errorf("failed to change token passphrase: %w", err)
+	}
+	return nil
+}
+
+// UnlockTokenStorage enters the passphrase of the encrypted token file
+func (a *App) UnlockTokenStorage(passphrase string) error {
+	if err := auth.UnlockTokens(passphrase); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	a.emitAccountChanged("auth-account-switched")
+	return nil
+}
+
+// usePlayerProfile selects the profile of the active account whose username is playerName.
+// Unknown names keep the active profile.
+func (a *App) usePlayerProfile(playerName string) error {
+	session, err := auth.LoadSession()
+	if err != nil || session == nil || session.Username == playerName {
+		return nil // Authentication is checked when launching
+	}
+	if len(session.Profiles) == 0 {
+		return nil // Signed in before profiles were stored
+	}
+
+	for _, profile := range session.Profiles {
+		if strings.EqualFold(profile.Username, playerName) {
+			if profile.UUID == session.UUID {
+				return nil
+			}
+			return a.SelectProfile(profile.UUID)
+		}
+	}
+	// The name may be stale, e.g. the frontend missed an account switch; play as the active profile
+	fmt.Printf("Unknown profile name, playing as profile %s\n", session.Username)
+	return nil
+}
+
+// emitAccountChanged tells the frontend which account is now active
+func (a *App) emitAccountChanged(event string) {
+	a.sessions.Invalidate()
+	if a.ctx == nil {
+		return
+	}
+
+	session, _ := auth.LoadSession()
+	if session == nil {
+		wailsRuntime.EventsEmit(a.ctx, event, nil)
+		return
+	}
+	wailsRuntime.EventsEmit(a.ctx, event, map[string]string{
+		"key":      session.Key(),
+		"username": session.Username,
+		"uuid":     session.UUID,
+	})
+}
+
 // GetAuthStatus returns the current authentication status
 func (a *App) GetAuthStatus() map[string]interface{} {
 	session, err := auth.LoadSession()
+	if errors.Is(err, auth.ErrTokensLocked) {
+		return map[string]interface{}{
+			"logged_in": false,
+			"locked":    true,
+		}
+	}
 	if err != nil || session == nil {
 		return map[string]interface{}{
 			"logged_in": false,
@@ -480,7 +899,7 @@ func (a *App) OpenAuthURL(url string) error {
 
 // GetUserProfile returns the current user's profile information
 func (a *App) GetUserProfile() (map[string]string, error) {
-	session, err := auth.GetValidSession()
+	session, err := a.sessions.Session()
 	if err != nil {
 		return nil, fmt.Errorf("not logged in: %w", err)
 	}
@@ -498,7 +917,7 @@ func (a *App) GetUserProfile() (map[string]string, error) {
 
 // GetUserUUID returns just the UUID of the current user
 func (a *App) GetUserUUID() (string, error) {
-	session, err := auth.GetValidSession()
+	session, err := a.sessions.Session()
 	if err != nil {
 		return "", fmt.Errorf("not logged in: %w", err)
 	}
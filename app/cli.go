@@ -0,0 +1,240 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"HyPrism/internal/auth"
+	"HyPrism/internal/config"
+	"HyPrism/internal/game"
+)
+
+const cliUsage = `HyPrism command line modes:
+  --dry-run                      Run every launch check and print the launch command without starting the game
+  --export-launch-script <path>  Write a shell script that starts the game with fresh tokens
+  --export-desktop-entry <path>  Write a .desktop file that starts the game through HyPrism
+  --launch                       Start the game without the launcher window and wait for it to exit
+  --login                        Log in by opening the printed address on any device and pasting the result back
+  --session-env                  Print fresh session tokens as shell variables (used by launch scripts)
+  --show-config                  Print every config value and whether it comes from the default, the file, env or a flag
+
+Config overrides, usable with every mode and the launcher window:
+  --set <key>=<value>            Override a config value for this run, e.g. --set patchline=pre-release
+  HYPRISM_<KEY>=<value>          Same as --set through the environment, e.g. HYPRISM_PATCHLINE; --set wins
+  --no-save-overrides            Keep overridden values out of the config file (or HYPRISM_NO_SAVE_OVERRIDES=1)
+
+Portable mode:
+  --portable                     Keep config, logins, logs and caches in HyPrismData next to the executable
+                                 (also turned on by a portable.txt file next to the executable)
+`
+
+// RunCLI handles command line modes that run without the launcher window.
+// It returns false if args do not request a CLI mode and the UI should start.
+func RunCLI(args []string) (handled bool, exitCode int) {
+	for i, arg := range args {
+		var value string
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+
+		switch arg {
+		case "--dry-run":
+			return true, cliDryRun()
+		case "--export-launch-script":
+			return true, cliExport("script", value)
+		case "--export-desktop-entry":
+			return true, cliExport("desktop", value)
+		case "--launch":
+			return true, cliLaunch()
+		case "--login":
+			return true, cliLogin()
+		case "--session-env":
+			return true, cliSessionEnv()
+		case "--show-config":
+			return true, cliShowConfig()
+		case "--help-cli":
+			fmt.Print(cliUsage)
+			return true, 0
+		}
+	}
+	return false, 0
+}
+
+// newCLIApp creates an App for command line modes, discovering the installation if none is configured.
+// Unlike the launcher window, command line modes do not run with invalid config overrides.
+func newCLIApp() (*App, error) {
+	a := NewApp()
+	if a.overrideErr != nil {
+		return nil, fmt.Errorf("invalid config override: %w", a.overrideErr)
+	}
+	a.discoverGameInstall()
+	return a, nil
+}
+
+// cliShowConfig prints the effective config and where each value came from
+func cliShowConfig() int {
+	a := NewApp()
+	if a.overrideErr != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config override: %v\n", a.overrideErr)
+		return 2
+	}
+	for _, setting := range a.GetConfigSources() {
+		fmt.Printf("%-20s %-8s %s\n", setting.Key, setting.Source, setting.Value)
+	}
+	fmt.Printf("\nConfig file: %s\n", config.GetConfigPath())
+	return 0
+}
+
+// cliDryRun prints the redacted launch command
+func cliDryRun() int {
+	a, err := newCLIApp()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	launch, err := a.prepareLaunch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Launch check failed: %v\n", err)
+		return 1
+	}
+
+	redacted := launch.Redacted()
+	fmt.Printf("Working directory: %s\n", redacted.Dir)
+	fmt.Println("Environment:")
+	for _, kv := range redacted.Env {
+		fmt.Printf("  %s\n", kv)
+	}
+	fmt.Printf("Command:\n  %s\n", launch.CommandLine())
+	return 0
+}
+
+// cliExport writes a launch script or desktop entry
+func cliExport(kind, dest string) int {
+	if dest == "" || strings.HasPrefix(dest, "--") {
+		fmt.Fprint(os.Stderr, "Missing output path\n\n"+cliUsage)
+		return 2
+	}
+
+	a, err := newCLIApp()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	launch, err := a.prepareLaunch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Launch check failed: %v\n", err)
+		return 1
+	}
+
+	if err := writeLaunchScript(kind, launch, dest); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", dest, err)
+		return 1
+	}
+
+	fmt.Printf("Written to %s\n", dest)
+	return 0
+}
+
+// cliLaunch starts the game and waits for it to exit
+func cliLaunch() int {
+	a, err := newCLIApp()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if a.cfg.GameInstallPath == "" {
+		fmt.Fprintln(os.Stderr, "Game not configured - set the Hytale install directory in HyPrism")
+		return 1
+	}
+
+	build := a.verifyBeforeLaunch(nil)
+
+	launch, err := a.prepareLaunch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Launch check failed: %v\n", err)
+		return 1
+	}
+	if err := game.LaunchInstance(a.gameSessions, launch); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to launch game: %v\n", err)
+		return 1
+	}
+
+	a.gameSessions.Wait()
+	info, ok := a.gameSessions.Current()
+	if !ok {
+		return 0
+	}
+	if build != nil && info.CleanExit() {
+		a.recordIntegrityManifest(build)
+	}
+	return info.ExitCode
+}
+
+// cliLogin logs in without a browser on this machine: the authorization URL is printed and
+// the address the browser ended up on, or the code, is read from stdin
+func cliLogin() int {
+	input := auth.NewCodeInput()
+	openBrowser := func(url string) error {
+		fmt.Printf("\nOpen this address in a browser on any device and log in:\n\n  %s\n\n", url)
+		fmt.Println("Then paste the address the browser ended up on, or the code it shows, and press Enter:")
+		return nil
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			err := input.Submit(context.Background(), scanner.Text())
+			if errors.Is(err, auth.ErrNoPendingLogin) {
+				return
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v - please try again:\n", err)
+			}
+		}
+	}()
+
+	progressCallback := func(message string) {
+		fmt.Printf("[AUTH] %s\n", message)
+	}
+	session, err := auth.LoginWithAuthCodeFlow(context.Background(), progressCallback, openBrowser, input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Login failed: %v\n", err)
+		return 1
+	}
+	if err := auth.SaveSession(session); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save session: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Logged in as %s (UUID: %s)\n", session.Username, session.UUID)
+	return 0
+}
+
+// cliSessionEnv prints fresh session tokens as shell variable assignments
+func cliSessionEnv() int {
+	// Progress messages must not end up in the output that scripts eval
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	session, err := auth.GetValidSession()
+	os.Stdout = stdout
+
+	if err != nil || session == nil {
+		fmt.Fprintln(os.Stderr, "Not logged in - open HyPrism or run HyPrism --login to log in with your Hytale account")
+		return 1
+	}
+
+	vars := []struct{ name, value string }{
+		{"HYPRISM_UUID", session.UUID},
+		{"HYPRISM_USERNAME", session.Username},
+		{"HYPRISM_SESSION_TOKEN", session.SessionToken},
+		{"HYPRISM_IDENTITY_TOKEN", session.IdentityToken},
+	}
+	for _, v := range vars {
+		fmt.Printf("%s='%s'\n", v.name, strings.ReplaceAll(v.value, "'", `'\''`))
+	}
+	return 0
+}
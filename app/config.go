@@ -1,7 +1,10 @@
 package app
 
 import (
+	"fmt"
+
 	"HyPrism/internal/config"
+	"HyPrism/internal/game"
 )
 
 // GetConfig returns the full config
@@ -11,16 +14,121 @@ func (a *App) GetConfig() *config.Config {
 
 // SaveConfig saves the configuration
 func (a *App) SaveConfig() error {
-	return config.Save(a.cfg)
+	return a.saveConfig()
+}
+
+// saveConfig writes the config file. In no-save-overrides mode, overridden values are
+// replaced with the ones from the file so that overrides only last for this run.
+func (a *App) saveConfig() error {
+	if !a.overrides.NoSave {
+		return config.Save(a.cfg)
+	}
+
+	cfg, err := a.overrides.WithoutOverrides(a.cfg, a.fileCfg)
+	if err != nil {
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	a.fileCfg = cfg
+	return nil
+}
+
+// GetConfigSources reports the effective value of every config key and whether it comes
+// from the default, the config file, an environment variable or a --set flag
+func (a *App) GetConfigSources() []config.Setting {
+	fileKeys, err := config.FileKeys()
+	if err != nil {
+		fmt.Printf("Warning: failed to read config file keys: %v\n", err)
+	}
+	return a.overrides.Settings(a.cfg, fileKeys)
+}
+
+// GetConfigOverrideError returns why the config overrides of this run were ignored, or nil
+func (a *App) GetConfigOverrideError() *AppError {
+	if a.overrideErr == nil {
+		return nil
+	}
+	return NewAppError(ErrorTypeValidation, "Config overrides were ignored", a.overrideErr)
 }
 
 // SetMusicEnabled sets music enabled state and saves it
 func (a *App) SetMusicEnabled(enabled bool) error {
 	a.cfg.MusicEnabled = enabled
-	return config.Save(a.cfg)
+	return a.saveConfig()
 }
 
 // GetMusicEnabled returns the music enabled state
 func (a *App) GetMusicEnabled() bool {
 	return a.cfg.MusicEnabled
 }
+
+// launchOptions returns the launch options stored in the config
+func (a *App) launchOptions() game.LaunchOptions {
+	return game.LaunchOptions{
+		ExtraArgs:      a.cfg.LaunchArgs,
+		Env:            a.cfg.LaunchEnv,
+		Wrapper:        a.cfg.LaunchWrapper,
+		DisplayBackend: a.cfg.DisplayBackend,
+		Patchline:      a.cfg.Patchline,
+		Build:          a.cfg.GameBuild,
+	}
+}
+
+// SetLaunchOptions validates and saves extra client arguments, environment variables and wrapper command
+func (a *App) SetLaunchOptions(args string, env map[string]string, wrapper string) error {
+	opts := game.LaunchOptions{
+		ExtraArgs: args,
+		Env:       env,
+		Wrapper:   wrapper,
+	}
+	if err := game.ValidateLaunchOptions(opts); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	a.cfg.LaunchArgs = args
+	a.cfg.LaunchEnv = env
+	a.cfg.LaunchWrapper = wrapper
+	return a.saveConfig()
+}
+
+// SetDisplayBackend sets the client display backend on Linux (auto, wayland or x11) and saves it
+func (a *App) SetDisplayBackend(backend string) error {
+	if err := game.ValidateLaunchOptions(game.LaunchOptions{DisplayBackend: backend}); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	a.cfg.DisplayBackend = backend
+	return a.saveConfig()
+}
+
+// GetDisplayBackend returns the configured display backend
+func (a *App) GetDisplayBackend() string {
+	if a.cfg.DisplayBackend == "" {
+		return game.DisplayBackendAuto
+	}
+	return a.cfg.DisplayBackend
+}
+
+// GetPatchlines returns every patchline and build found in the configured installation
+func (a *App) GetPatchlines() ([]game.Patchline, error) {
+	if a.cfg.GameInstallPath == "" {
+		return []game.Patchline{}, nil
+	}
+	return game.ScanPatchlines(a.cfg.GameInstallPath)
+}
+
+// SetGameBuild selects the patchline and build to launch and saves it
+func (a *App) SetGameBuild(patchline string, build string) error {
+	if a.cfg.GameInstallPath == "" {
+		return ValidationError("Please set the Hytale install directory first")
+	}
+	if _, err := game.ResolveBuild(a.cfg.GameInstallPath, patchline, build); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	a.cfg.Patchline = patchline
+	a.cfg.GameBuild = build
+	return a.saveConfig()
+}
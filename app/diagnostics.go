@@ -2,6 +2,7 @@ package app
 
 import (
 	"HyPrism/internal/env"
+	"HyPrism/internal/game"
 	"HyPrism/internal/java"
 	"fmt"
 	"net"
@@ -10,6 +11,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // DiagnosticReport contains system diagnostic information
@@ -123,7 +126,7 @@ func checkDependencies() DependenciesInfo {
 func (a *App) SaveDiagnosticReport() (string, error) {
 	report := a.RunDiagnostics()
 	
-	logsDir := filepath.Join(env.GetDefaultAppDir(), "logs")
+	logsDir := env.GetLogsDir()
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
 		return "", err
 	}
@@ -176,8 +179,44 @@ type CrashReport struct {
 	Preview   string `json:"preview"`
 }
 
-// GetCrashReports returns available crash reports
+// GetCrashReports returns available crash reports, newest first
 func (a *App) GetCrashReports() ([]CrashReport, error) {
-	// Crash reports not applicable for official installation launcher
-	return []CrashReport{}, nil
+	reports, err := game.ListCrashReports()
+	if err != nil {
+		return nil, FileSystemError("reading crash reports", err)
+	}
+
+	result := make([]CrashReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, CrashReport{
+			Filename:  report.ID,
+			Timestamp: report.Timestamp,
+			Preview:   report.Preview,
+		})
+	}
+	return result, nil
+}
+
+// ExportCrashReport asks for a destination and saves a crash report as a ZIP archive
+func (a *App) ExportCrashReport(filename string) (string, error) {
+	dest, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "Export Crash Report",
+		DefaultFilename: fmt.Sprintf("hyprism_%s.zip", filename),
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: "ZIP Archives (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+
+	if dest == "" {
+		return "", nil // User cancelled
+	}
+
+	if err := game.ExportCrashReport(filename, dest); err != nil {
+		return "", FileSystemError("exporting crash report", err)
+	}
+
+	return dest, nil
 }
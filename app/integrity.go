@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+
+	"HyPrism/internal/game"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// selectedGameBuild resolves the configured patchline and build
+func (a *App) selectedGameBuild() (*game.GameBuild, error) {
+	if a.cfg.GameInstallPath == "" {
+		return nil, GameError("Game not configured", fmt.Errorf("please set the Hytale install directory in settings"))
+	}
+
+	build, err := game.ResolveBuild(a.cfg.GameInstallPath, a.cfg.Patchline, a.cfg.GameBuild)
+	if err != nil {
+		return nil, GameError("Game build not found", err)
+	}
+	return build, nil
+}
+
+// VerifyGameInstall checks the selected build against its integrity manifest.
+// Progress is reported through the progress-update event.
+func (a *App) VerifyGameInstall() (*game.IntegrityReport, error) {
+	build, err := a.selectedGameBuild()
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := game.VerifyInstall(a.cfg.GameInstallPath, build, a.progressCallback)
+	if err != nil {
+		return nil, GameError("Failed to verify game files", err)
+	}
+
+	fmt.Printf("Verified %d files of %s/%s (manifest: %s), %d issue(s)\n",
+		report.CheckedFiles, report.Patchline, report.Build, report.ManifestSource, len(report.Issues))
+	return report, nil
+}
+
+// RestoreOriginalGameFiles restores every file of the selected build from its .original backup
+// and returns the restored files
+func (a *App) RestoreOriginalGameFiles() ([]string, error) {
+	if a.gameSessions.IsRunning() {
+		return nil, ValidationError("Close the game before restoring its files")
+	}
+
+	build, err := a.selectedGameBuild()
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := game.RestoreOriginals(build, a.progressCallback)
+	if err != nil {
+		return restored, GameError("Failed to restore original files", err)
+	}
+	return restored, nil
+}
+
+// ImportIntegrityManifest asks for a trusted manifest file and uses it to verify the selected build
+func (a *App) ImportIntegrityManifest() (bool, error) {
+	build, err := a.selectedGameBuild()
+	if err != nil {
+		return false, err
+	}
+
+	src, err := wailsRuntime.OpenFileDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+		Title: "Import Integrity Manifest",
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: "Integrity Manifests (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to open file dialog: %w", err)
+	}
+
+	if src == "" {
+		return false, nil // User cancelled
+	}
+
+	if err := game.ImportTrustedManifest(a.cfg.GameInstallPath, build, src); err != nil {
+		return false, FileSystemError("importing integrity manifest", err)
+	}
+	return true, nil
+}
+
+// verifyBeforeLaunch checks the build about to be launched against its integrity manifest.
+// Problems are reported through the integrity-report event but do not stop the launch.
+func (a *App) verifyBeforeLaunch(progress game.ProgressCallback) *game.GameBuild {
+	build, err := game.ResolveBuild(a.cfg.GameInstallPath, a.cfg.Patchline, a.cfg.GameBuild)
+	if err != nil {
+		return nil
+	}
+
+	report, err := game.VerifyInstall(a.cfg.GameInstallPath, build, progress)
+	if err != nil {
+		fmt.Printf("Warning: failed to verify game files: %v\n", err)
+		return build
+	}
+
+	if !report.OK {
+		fmt.Printf("Game files of %s/%s have %d issue(s):\n", report.Patchline, report.Build, len(report.Issues))
+		for _, issue := range report.Issues {
+			fmt.Printf("  %s: %s\n", issue.Problem, issue.Path)
+		}
+		a.emitGameEvent("integrity-report", report)
+	}
+	return build
+}
+
+// recordIntegrityManifest builds the manifest of a build after it ran and exited cleanly
+func (a *App) recordIntegrityManifest(build *game.GameBuild) {
+	a.integrityMu.Lock()
+	defer a.integrityMu.Unlock()
+
+	if err := game.EnsureManifest(a.cfg.GameInstallPath, build); err != nil {
+		fmt.Printf("Warning: failed to build integrity manifest: %v\n", err)
+	}
+}
+
+// onGameEvent records the integrity manifest of a build after a clean game exit
+// and forwards every game event to the frontend
+func (a *App) onGameEvent(event string, data interface{}) {
+	if info, ok := data.(game.SessionInfo); ok && event == game.EventGameExited {
+		if build := a.launchedBuild.Swap(nil); build != nil && info.CleanExit() {
+			go a.recordIntegrityManifest(build)
+		}
+	}
+	a.emitGameEvent(event, data)
+}
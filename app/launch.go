@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/game"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// prepareLaunch runs every pre-launch check and returns the command the game would be started with
+func (a *App) prepareLaunch() (*game.LaunchCommand, error) {
+	if a.cfg.GameInstallPath == "" {
+		return nil, GameError("Game not configured", fmt.Errorf("please set the Hytale install directory in settings"))
+	}
+
+	// Require authentication - no offline mode
+	session, err := a.sessions.Session()
+	if err != nil || session == nil {
+		return nil, GameError("Authentication required", fmt.Errorf("please log in with your Hytale account"))
+	}
+	fmt.Printf("Using authenticated account: %s (UUID: %s)\n", session.Username, session.UUID)
+
+	launch, err := game.PrepareLaunch(a.cfg.GameInstallPath, session, a.launchOptions())
+	if err != nil {
+		return nil, GameError("Launch check failed", err)
+	}
+
+	return launch, nil
+}
+
+// DryRunLaunch runs every launch check and returns the command line, working directory
+// and environment the game would be started with, without starting it. Tokens are redacted.
+func (a *App) DryRunLaunch() (*game.LaunchCommand, error) {
+	launch, err := a.prepareLaunch()
+	if err != nil {
+		return nil, err
+	}
+	return launch.Redacted(), nil
+}
+
+// ExportLaunchScript asks for a destination and writes a runnable launch script ("script")
+// or desktop entry ("desktop") that fetches fresh tokens from HyPrism whenever it runs
+func (a *App) ExportLaunchScript(kind string) (string, error) {
+	var defaultName, filterName, pattern string
+	switch kind {
+	case "script":
+		defaultName, filterName, pattern = "hytale.sh", "Shell Scripts (*.sh)", "*.sh"
+	case "desktop":
+		defaultName, filterName, pattern = "hytale-hyprism.desktop", "Desktop Entries (*.desktop)", "*.desktop"
+	default:
+		return "", ValidationError(fmt.Sprintf("unknown launch script type: %s", kind))
+	}
+
+	if runtime.GOOS == "windows" {
+		return "", ValidationError("Launch scripts are only supported on Linux and macOS")
+	}
+
+	// Validate the launch before asking where to save it
+	launch, err := a.prepareLaunch()
+	if err != nil {
+		return "", err
+	}
+
+	dest, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "Export Launch Script",
+		DefaultFilename: defaultName,
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: filterName, Pattern: pattern},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+
+	if dest == "" {
+		return "", nil // User cancelled
+	}
+
+	if err := writeLaunchScript(kind, launch, dest); err != nil {
+		return "", FileSystemError("writing launch script", err)
+	}
+
+	fmt.Printf("Launch %s written to %s\n", kind, dest)
+	return dest, nil
+}
+
+// writeLaunchScript writes a launch script or desktop entry for launch to dest
+func writeLaunchScript(kind string, launch *game.LaunchCommand, dest string) error {
+	launcher, err := launcherCommand()
+	if err != nil {
+		return err
+	}
+
+	if kind == "desktop" {
+		return game.WriteDesktopEntry(launcher, "dev.hyprism.HyPrism", dest)
+	}
+	return game.WriteLaunchScript(launch, launcher, dest)
+}
+
+// launcherCommand returns the command that runs this launcher from outside its sandbox
+func launcherCommand() ([]string, error) {
+	if env.IsFlatpak() {
+		appID := os.Getenv("FLATPAK_ID")
+		if appID == "" {
+			appID = "dev.hyprism.HyPrism"
+		}
+		return []string{"flatpak", "run", appID}, nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	// AppImages are extracted to a temporary mount; point at the image itself
+	if appImage := os.Getenv("APPIMAGE"); appImage != "" {
+		exePath = appImage
+	}
+
+	// Keep scripts and desktop entries on the same data when portable mode came from the flag
+	if env.IsPortable() {
+		return []string{exePath, env.PortableFlag}, nil
+	}
+	return []string{exePath}, nil
+}
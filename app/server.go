@@ -0,0 +1,370 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"HyPrism/internal/mods"
+	"HyPrism/internal/server"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ServerStatus is a local server with its process state
+type ServerStatus struct {
+	Settings server.Settings     `json:"settings"`
+	Dir      string              `json:"dir"`
+	Running  bool                `json:"running"`
+	Process  *server.ProcessInfo `json:"process,omitempty"`
+
+	RestartPending bool `json:"restartPending"` // crashed and waiting for the watchdog to restart it
+}
+
+// ListServers returns every local server
+func (a *App) ListServers() ([]ServerStatus, error) {
+	servers, err := server.List()
+	if err != nil {
+		return nil, FileSystemError("listing servers", err)
+	}
+
+	statuses := make([]ServerStatus, 0, len(servers))
+	for _, s := range servers {
+		status := ServerStatus{Settings: s, Dir: server.GetServerDir(s.Name)}
+		if info, ok := a.servers.Status(s.Name); ok {
+			status.Running = true
+			status.Process = &info
+		}
+		status.RestartPending = a.servers.IsRestartPending(s.Name)
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// CreateServer creates a standalone server directory from the selected patchline and build
+func (a *App) CreateServer(name string) (*server.Settings, error) {
+	if a.cfg.GameInstallPath == "" {
+		return nil, GameError("Game not configured", fmt.Errorf("please set the Hytale install directory in settings"))
+	}
+	if err := server.ValidateName(name); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+
+	s, err := server.Create(name, a.cfg.GameInstallPath, a.cfg.Patchline, a.cfg.GameBuild)
+	if err != nil {
+		return nil, GameError("Failed to create server", err)
+	}
+	return s, nil
+}
+
+// UpdateServerSettings sets the heap sizes and extra arguments of a server
+func (a *App) UpdateServerSettings(name, minHeap, maxHeap, jvmArgs, serverArgs string) error {
+	s, err := server.LoadSettings(name)
+	if err != nil {
+		return GameError("Server not found", err)
+	}
+
+	s.MinHeap = minHeap
+	s.MaxHeap = maxHeap
+	s.JVMArgs = jvmArgs
+	s.ServerArgs = serverArgs
+	if err := server.ValidateSettings(s); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	if err := server.SaveSettings(s); err != nil {
+		return FileSystemError("saving server settings", err)
+	}
+	return nil
+}
+
+// UpdateServerJar copies the server jar of the server's build again
+func (a *App) UpdateServerJar(name string) error {
+	if a.servers.IsRunning(name) || a.servers.IsRestartPending(name) {
+		return ValidationError("Stop the server before updating it")
+	}
+	if err := server.UpdateJar(name, a.cfg.GameInstallPath); err != nil {
+		return GameError("Failed to update server", err)
+	}
+	return nil
+}
+
+// DeleteServer removes a server directory including its worlds
+func (a *App) DeleteServer(name string) error {
+	if a.servers.IsRunning(name) || a.servers.IsRestartPending(name) {
+		return ValidationError("Stop the server before deleting it")
+	}
+	if err := server.Delete(name); err != nil {
+		return FileSystemError("deleting server", err)
+	}
+	return nil
+}
+
+// StartServer starts a server. Console output is streamed through the server-console event.
+func (a *App) StartServer(name string) (*server.ProcessInfo, error) {
+	if a.cfg.GameInstallPath == "" {
+		return nil, GameError("Game not configured", fmt.Errorf("please set the Hytale install directory in settings"))
+	}
+
+	info, err := a.servers.Start(name, a.cfg.GameInstallPath)
+	if err != nil {
+		return nil, GameError("Failed to start server", err)
+	}
+	return &info, nil
+}
+
+// StopServer shuts a server down gracefully, killing it after timeoutSeconds (0 uses the default)
+func (a *App) StopServer(name string, timeoutSeconds int) error {
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	if err := a.servers.Stop(name, timeout); err != nil {
+		return GameError("Failed to stop server", err)
+	}
+	return nil
+}
+
+// SendServerCommand writes a command to a server's console
+func (a *App) SendServerCommand(name, command string) error {
+	if err := a.servers.SendCommand(name, command); err != nil {
+		return GameError("Failed to send command", err)
+	}
+	return nil
+}
+
+// GetServerConsole returns the recent console output of a server
+func (a *App) GetServerConsole(name string) []server.ConsoleLine {
+	return a.servers.Console(name)
+}
+
+// OpenServerFolder opens a server directory in the file manager
+func (a *App) OpenServerFolder(name string) error {
+	if _, err := server.LoadSettings(name); err != nil {
+		return GameError("Server not found", err)
+	}
+	return openFolder(server.GetServerDir(name))
+}
+
+// UpdateServerBackupSettings sets the backup schedule and retention of a server
+func (a *App) UpdateServerBackupSettings(name string, intervalMinutes int, onModChange bool, keepLast, keepDaily, keepWeekly int) error {
+	s, err := server.LoadSettings(name)
+	if err != nil {
+		return GameError("Server not found", err)
+	}
+
+	s.BackupIntervalMinutes = intervalMinutes
+	s.BackupOnModChange = onModChange
+	s.KeepLastBackups = keepLast
+	s.KeepDailyBackups = keepDaily
+	s.KeepWeeklyBackups = keepWeekly
+	if err := server.ValidateSettings(s); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	if err := server.SaveSettings(s); err != nil {
+		return FileSystemError("saving server settings", err)
+	}
+	return nil
+}
+
+// UpdateServerWatchdogSettings sets whether a crashed server is restarted and how many
+// crashes within windowMinutes are tolerated before the watchdog gives up
+func (a *App) UpdateServerWatchdogSettings(name string, autoRestart bool, maxCrashes, windowMinutes int) error {
+	s, err := server.LoadSettings(name)
+	if err != nil {
+		return GameError("Server not found", err)
+	}
+
+	s.AutoRestart = autoRestart
+	s.MaxCrashes = maxCrashes
+	s.CrashWindowMinutes = windowMinutes
+	if err := server.ValidateSettings(s); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	if err := server.SaveSettings(s); err != nil {
+		return FileSystemError("saving server settings", err)
+	}
+	return nil
+}
+
+// GetServerCrashHistory returns the recorded crashes of a server, oldest first
+func (a *App) GetServerCrashHistory(name string) ([]server.CrashRecord, error) {
+	history, err := server.LoadCrashHistory(name)
+	if err != nil {
+		return nil, FileSystemError("reading crash history", err)
+	}
+	return history, nil
+}
+
+// ClearServerCrashHistory removes the recorded crashes of a server
+func (a *App) ClearServerCrashHistory(name string) error {
+	if err := server.ClearCrashHistory(name); err != nil {
+		return FileSystemError("clearing crash history", err)
+	}
+	return nil
+}
+
+// ListServerBackups returns the world backups of a server, newest first
+func (a *App) ListServerBackups(name string) ([]server.BackupInfo, error) {
+	backups, err := server.ListBackups(name)
+	if err != nil {
+		return nil, FileSystemError("listing backups", err)
+	}
+	return backups, nil
+}
+
+// BackupServer takes a world backup of a server now
+func (a *App) BackupServer(name string) (*server.BackupInfo, error) {
+	backup, err := a.servers.Backup(name, server.BackupManual)
+	if err != nil {
+		return nil, FileSystemError("backing up server", err)
+	}
+	return backup, nil
+}
+
+// RestoreServerBackup replaces the world of a server with a backup.
+// It refuses to run while a Java process is using the server directory.
+func (a *App) RestoreServerBackup(name, backupID string) error {
+	if err := a.servers.Restore(name, backupID); err != nil {
+		return GameError("Failed to restore backup", err)
+	}
+	return nil
+}
+
+// DeleteServerBackup removes a world backup
+func (a *App) DeleteServerBackup(name, backupID string) error {
+	if err := server.DeleteBackup(name, backupID); err != nil {
+		return FileSystemError("deleting backup", err)
+	}
+	return nil
+}
+
+// ==================== SERVER MODS ====================
+
+// GetServerMods returns the mods installed on a server
+func (a *App) GetServerMods(name string) ([]mods.Mod, error) {
+	if _, err := server.LoadSettings(name); err != nil {
+		return nil, GameError("Server not found", err)
+	}
+	return mods.GetServerInstalledMods(server.GetModsDir(name))
+}
+
+// InstallModFileToTarget installs a specific mod file version to the client ("client"),
+// a server ("server") or both ("both")
+func (a *App) InstallModFileToTarget(modID int, fileID int, branch string, version int, target string, serverName string) error {
+	if err := mods.ValidateTarget(target); err != nil {
+		return ValidationError(err.Error())
+	}
+	if target != mods.TargetClient {
+		if err := a.checkServerModsWritable(serverName); err != nil {
+			return err
+		}
+		if err := a.servers.BackupForModChange(serverName); err != nil {
+			return FileSystemError("backing up server world before mod change", err)
+		}
+	}
+
+	progress := func(progress float64, message string) {
+		wailsRuntime.EventsEmit(a.ctx, "mod-progress", map[string]interface{}{
+			"progress": progress,
+			"message":  message,
+		})
+	}
+
+	if target == mods.TargetServer {
+		return mods.DownloadModFileToServer(a.ctx, modID, fileID, server.GetModsDir(serverName), progress)
+	}
+
+	if err := mods.DownloadModFileToInstance(a.ctx, modID, fileID, branch, version, progress); err != nil {
+		return err
+	}
+	if target == mods.TargetClient {
+		return nil
+	}
+
+	// Copy the client's download instead of fetching it twice
+	clientMods, err := mods.GetInstanceInstalledMods(branch, version)
+	if err != nil {
+		return err
+	}
+	for _, m := range clientMods {
+		if m.ID == fmt.Sprintf("cf-%d", modID) {
+			return mods.CopyModToServer(m, server.GetModsDir(serverName))
+		}
+	}
+	return fmt.Errorf("mod cf-%d not found after install", modID)
+}
+
+// UninstallServerMod removes a mod from a server
+func (a *App) UninstallServerMod(name, modID string) error {
+	if err := a.checkServerModsWritable(name); err != nil {
+		return err
+	}
+	if err := a.servers.BackupForModChange(name); err != nil {
+		return FileSystemError("backing up server world before mod change", err)
+	}
+	return mods.RemoveServerMod(modID, server.GetModsDir(name))
+}
+
+// PreviewServerModSync returns the changes that would make a server's mods match a set
+// of client mods. Empty modIDs selects every enabled client mod.
+func (a *App) PreviewServerModSync(name string, branch string, version int, modIDs []string) (*mods.SyncPlan, error) {
+	if _, err := server.LoadSettings(name); err != nil {
+		return nil, GameError("Server not found", err)
+	}
+
+	clientMods, err := mods.GetInstanceInstalledMods(branch, version)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := mods.PlanServerSync(clientMods, modIDs, server.GetModsDir(name))
+	if err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	return plan, nil
+}
+
+// SyncServerMods applies the changes previewed by PreviewServerModSync and returns them.
+// planHash is the hash of the previewed plan; if the mods changed since, nothing is applied.
+func (a *App) SyncServerMods(name string, branch string, version int, modIDs []string, planHash string) (*mods.SyncPlan, error) {
+	if err := a.checkServerModsWritable(name); err != nil {
+		return nil, err
+	}
+
+	plan, err := a.PreviewServerModSync(name, branch, version, modIDs)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Hash != planHash {
+		return nil, ValidationError("The client or server mods changed since the preview - review the changes again")
+	}
+	if !plan.HasChanges() {
+		return plan, nil
+	}
+
+	if err := a.servers.BackupForModChange(name); err != nil {
+		return nil, FileSystemError("backing up server world before mod change", err)
+	}
+
+	clientMods, err := mods.GetInstanceInstalledMods(branch, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := mods.ApplyServerSync(plan, clientMods, server.GetModsDir(name)); err != nil {
+		return nil, FileSystemError("syncing server mods", err)
+	}
+
+	fmt.Printf("Synced mods of server %s: %d added, %d updated, %d removed, %d skipped (conflicts)\n",
+		name, len(plan.Add), len(plan.Update), len(plan.Remove), len(plan.Conflicts))
+	return plan, nil
+}
+
+// checkServerModsWritable checks that a server exists and is stopped
+func (a *App) checkServerModsWritable(name string) error {
+	if _, err := server.LoadSettings(name); err != nil {
+		return GameError("Server not found", err)
+	}
+	if a.servers.IsRunning(name) || a.servers.IsRestartPending(name) {
+		return ValidationError("Stop the server before changing its mods")
+	}
+	return nil
+}
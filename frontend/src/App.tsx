@@ -14,11 +14,11 @@ import {
   Launch,
   Update,
   ExitGame,
-  IsGameRunning,
   // Settings
   SelectGameInstallDirectory,
   GetNews,
   GetLauncherVersion,
+  GetConfigOverrideError,
   // Authentication
   LoginWithHytaleAccount,
   LogoutHytaleAccount,
@@ -55,24 +55,6 @@ const App: React.FC = () => {
 
 
 
-  // Game state polling
-  useEffect(() => {
-    if (!isGameRunning) return;
-
-    const pollInterval = setInterval(async () => {
-      try {
-        const running = await IsGameRunning();
-        if (!running) {
-          setIsGameRunning(false);
-        }
-      } catch (e) {
-        console.error('Failed to check game state:', e);
-      }
-    }, 3000);
-
-    return () => clearInterval(pollInterval);
-  }, [isGameRunning]);
-
   useEffect(() => {
     // Check auth status on startup
     const checkAuth = async () => {
@@ -92,6 +74,13 @@ const App: React.FC = () => {
     // Initialize launcher version
     GetLauncherVersion().then((v: string) => setLauncherVersion(v));
 
+    // Invalid HYPRISM_* variables or --set flags are ignored; tell the user why
+    GetConfigOverrideError().then((err: any) => {
+      if (err) {
+        setError({ ...err, timestamp: err.timestamp || new Date().toISOString() });
+      }
+    });
+
     // Event listeners
     const unsubProgress = EventsOn('progress-update', (data: any) => {
       if (data.stage === 'launch') {
@@ -99,6 +88,14 @@ const App: React.FC = () => {
       }
     });
 
+    const unsubGameStarted = EventsOn('game-started', () => {
+      setIsGameRunning(true);
+    });
+
+    const unsubGameExited = EventsOn('game-exited', () => {
+      setIsGameRunning(false);
+    });
+
     const unsubUpdate = EventsOn('update:available', (asset: any) => {
       setUpdateAsset(asset);
       // Don't auto-update - let user click the update button
@@ -141,14 +138,30 @@ const App: React.FC = () => {
       setUsername('HyPrism');
     });
 
+    const unsubAccountSwitched = EventsOn('auth-account-switched', (data: any) => {
+      console.log('Account switched:', data);
+      if (data) {
+        setIsLoggedIn(true);
+        setUsername(data.username || 'HyPrism');
+        setUserUUID(data.uuid || '');
+      } else {
+        setIsLoggedIn(false);
+        setUserUUID('');
+        setUsername('HyPrism');
+      }
+    });
+
     return () => {
       unsubProgress();
+      unsubGameStarted();
+      unsubGameExited();
       unsubUpdate();
       unsubUpdateProgress();
       unsubError();
       unsubAuthProgress();
       unsubAuthSuccess();
       unsubAuthLogout();
+      unsubAccountSwitched();
     };
   }, []);
 
@@ -1,10 +1,17 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {server} from '../models';
 import {mods} from '../models';
 import {updater} from '../models';
+import {game} from '../models';
 import {config} from '../models';
 import {app} from '../models';
 import {news} from '../models';
+import {auth} from '../models';
+
+export function BackupServer(arg1:string):Promise<server.BackupInfo>;
+
+export function CancelLogin():Promise<void>;
 
 export function CheckInstanceModUpdates(arg1:string,arg2:number):Promise<Array<mods.Mod>>;
 
@@ -12,15 +19,39 @@ export function CheckModUpdates():Promise<Array<mods.Mod>>;
 
 export function CheckUpdate():Promise<updater.Asset>;
 
+export function ClearServerCrashHistory(arg1:string):Promise<void>;
+
+export function CreateServer(arg1:string):Promise<server.Settings>;
+
+export function DeleteServer(arg1:string):Promise<void>;
+
+export function DeleteServerBackup(arg1:string,arg2:string):Promise<void>;
+
+export function DiscoverGameInstalls():Promise<Array<game.InstallCandidate>>;
+
+export function DryRunLaunch():Promise<game.LaunchCommand>;
+
 export function ExitGame():Promise<void>;
 
+export function ExportCrashReport(arg1:string):Promise<string>;
+
+export function ExportLaunchScript(arg1:string):Promise<string>;
+
 export function GetAuthStatus():Promise<Record<string, any>>;
 
 export function GetConfig():Promise<config.Config>;
 
+export function GetConfigOverrideError():Promise<app.AppError>;
+
+export function GetConfigSources():Promise<Array<config.Setting>>;
+
 export function GetCrashReports():Promise<Array<app.CrashReport>>;
 
-export function GetGameLogs():Promise<string>;
+export function GetDisplayBackend():Promise<string>;
+
+export function GetGameLogs(arg1:string):Promise<string>;
+
+export function GetGameSession():Promise<game.SessionInfo>;
 
 export function GetInstalledMods():Promise<Array<mods.Mod>>;
 
@@ -40,27 +71,55 @@ export function GetMusicEnabled():Promise<boolean>;
 
 export function GetNews(arg1:number):Promise<Array<news.NewsItem>>;
 
+export function GetPatchlines():Promise<Array<game.Patchline>>;
+
 export function GetPlatformInfo():Promise<Record<string, string>>;
 
+export function GetProfiles():Promise<Array<auth.Profile>>;
+
+export function GetServerConsole(arg1:string):Promise<Array<server.ConsoleLine>>;
+
+export function GetServerCrashHistory(arg1:string):Promise<Array<server.CrashRecord>>;
+
+export function GetServerMods(arg1:string):Promise<Array<mods.Mod>>;
+
+export function GetTokenStorage():Promise<auth.TokenStorageInfo>;
+
 export function GetUserProfile():Promise<Record<string, string>>;
 
 export function GetUserUUID():Promise<string>;
 
+export function ImportIntegrityManifest():Promise<boolean>;
+
 export function InstallMod(arg1:number):Promise<void>;
 
 export function InstallModFile(arg1:number,arg2:number):Promise<void>;
 
 export function InstallModFileToInstance(arg1:number,arg2:number,arg3:string,arg4:number):Promise<void>;
 
+export function InstallModFileToTarget(arg1:number,arg2:number,arg3:string,arg4:number,arg5:string,arg6:string):Promise<void>;
+
 export function InstallModToInstance(arg1:number,arg2:string,arg3:number):Promise<void>;
 
 export function IsGameRunning():Promise<boolean>;
 
+export function IsPortableMode():Promise<boolean>;
+
 export function Launch(arg1:string):Promise<void>;
 
+export function ListAccounts():Promise<Array<auth.AccountInfo>>;
+
+export function ListGameLogs():Promise<Array<game.GameLogInfo>>;
+
+export function ListServerBackups(arg1:string):Promise<Array<server.BackupInfo>>;
+
+export function ListServers():Promise<Array<app.ServerStatus>>;
+
 export function LoginWithHytaleAccount():Promise<void>;
 
-export function LogoutHytaleAccount():Promise<void>;
+export function LoginWithHytaleAccountManual():Promise<void>;
+
+export function LogoutHytaleAccount():Promise<auth.LogoutReport>;
 
 export function OpenAuthURL(arg1:string):Promise<void>;
 
@@ -70,8 +129,18 @@ export function OpenInstanceModsFolder(arg1:string,arg2:number):Promise<void>;
 
 export function OpenModsFolder():Promise<void>;
 
+export function OpenServerFolder(arg1:string):Promise<void>;
+
+export function PreviewServerModSync(arg1:string,arg2:string,arg3:number,arg4:Array<string>):Promise<mods.SyncPlan>;
+
 export function QuickLaunch():Promise<void>;
 
+export function RemoveAccount(arg1:string):Promise<auth.LogoutReport>;
+
+export function RestoreOriginalGameFiles():Promise<Array<string>>;
+
+export function RestoreServerBackup(arg1:string,arg2:string):Promise<void>;
+
 export function RunDiagnostics():Promise<app.DiagnosticReport>;
 
 export function SaveConfig():Promise<void>;
@@ -84,8 +153,30 @@ export function SelectGameInstallDirectory():Promise<string>;
 
 export function SelectInstanceDirectory():Promise<string>;
 
+export function SelectProfile(arg1:string):Promise<void>;
+
+export function SendServerCommand(arg1:string,arg2:string):Promise<void>;
+
+export function SetDisplayBackend(arg1:string):Promise<void>;
+
+export function SetGameBuild(arg1:string,arg2:string):Promise<void>;
+
+export function SetLaunchOptions(arg1:string,arg2:Record<string, string>,arg3:string):Promise<void>;
+
 export function SetMusicEnabled(arg1:boolean):Promise<void>;
 
+export function SetTokenPassphrase(arg1:string):Promise<void>;
+
+export function StartServer(arg1:string):Promise<server.ProcessInfo>;
+
+export function StopServer(arg1:string,arg2:number):Promise<void>;
+
+export function SubmitLoginCode(arg1:string):Promise<void>;
+
+export function SwitchAccount(arg1:string):Promise<void>;
+
+export function SyncServerMods(arg1:string,arg2:string,arg3:number,arg4:Array<string>,arg5:string):Promise<mods.SyncPlan>;
+
 export function ToggleInstanceMod(arg1:string,arg2:boolean,arg3:string,arg4:number):Promise<void>;
 
 export function ToggleMod(arg1:string,arg2:boolean):Promise<void>;
@@ -94,4 +185,20 @@ export function UninstallInstanceMod(arg1:string,arg2:string,arg3:number):Promis
 
 export function UninstallMod(arg1:string):Promise<void>;
 
+export function UninstallServerMod(arg1:string,arg2:string):Promise<void>;
+
+export function UnlockTokenStorage(arg1:string):Promise<void>;
+
 export function Update():Promise<void>;
+
+export function UpdateServerBackupSettings(arg1:string,arg2:number,arg3:boolean,arg4:number,arg5:number,arg6:number):Promise<void>;
+
+export function UpdateServerJar(arg1:string):Promise<void>;
+
+export function UpdateServerSettings(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<void>;
+
+export function UpdateServerWatchdogSettings(arg1:string,arg2:boolean,arg3:number,arg4:number):Promise<void>;
+
+export function UseGameInstall(arg1:string):Promise<void>;
+
+export function VerifyGameInstall():Promise<game.IntegrityReport>;
@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function BackupServer(arg1) {
+  return window['go']['app']['App']['BackupServer'](arg1);
+}
+
+export function CancelLogin() {
+  return window['go']['app']['App']['CancelLogin']();
+}
+
 export function CheckInstanceModUpdates(arg1, arg2) {
   return window['go']['app']['App']['CheckInstanceModUpdates'](arg1, arg2);
 }
@@ -14,10 +22,42 @@ export function CheckUpdate() {
   return window['go']['app']['App']['CheckUpdate']();
 }
 
+export function ClearServerCrashHistory(arg1) {
+  return window['go']['app']['App']['ClearServerCrashHistory'](arg1);
+}
+
+export function CreateServer(arg1) {
+  return window['go']['app']['App']['CreateServer'](arg1);
+}
+
+export function DeleteServer(arg1) {
+  return window['go']['app']['App']['DeleteServer'](arg1);
+}
+
+export function DeleteServerBackup(arg1, arg2) {
+  return window['go']['app']['App']['DeleteServerBackup'](arg1, arg2);
+}
+
+export function DiscoverGameInstalls() {
+  return window['go']['app']['App']['DiscoverGameInstalls']();
+}
+
+export function DryRunLaunch() {
+  return window['go']['app']['App']['DryRunLaunch']();
+}
+
 export function ExitGame() {
   return window['go']['app']['App']['ExitGame']();
 }
 
+export function ExportCrashReport(arg1) {
+  return window['go']['app']['App']['ExportCrashReport'](arg1);
+}
+
+export function ExportLaunchScript(arg1) {
+  return window['go']['app']['App']['ExportLaunchScript'](arg1);
+}
+
 export function GetAuthStatus() {
   return window['go']['app']['App']['GetAuthStatus']();
 }
@@ -26,12 +66,28 @@ export function GetConfig() {
   return window['go']['app']['App']['GetConfig']();
 }
 
+export function GetConfigOverrideError() {
+  return window['go']['app']['App']['GetConfigOverrideError']();
+}
+
+export function GetConfigSources() {
+  return window['go']['app']['App']['GetConfigSources']();
+}
+
 export function GetCrashReports() {
   return window['go']['app']['App']['GetCrashReports']();
 }
 
-export function GetGameLogs() {
-  return window['go']['app']['App']['GetGameLogs']();
+export function GetDisplayBackend() {
+  return window['go']['app']['App']['GetDisplayBackend']();
+}
+
+export function GetGameLogs(arg1) {
+  return window['go']['app']['App']['GetGameLogs'](arg1);
+}
+
+export function GetGameSession() {
+  return window['go']['app']['App']['GetGameSession']();
 }
 
 export function GetInstalledMods() {
@@ -70,10 +126,34 @@ export function GetNews(arg1) {
   return window['go']['app']['App']['GetNews'](arg1);
 }
 
+export function GetPatchlines() {
+  return window['go']['app']['App']['GetPatchlines']();
+}
+
 export function GetPlatformInfo() {
   return window['go']['app']['App']['GetPlatformInfo']();
 }
 
+export function GetProfiles() {
+  return window['go']['app']['App']['GetProfiles']();
+}
+
+export function GetServerConsole(arg1) {
+  return window['go']['app']['App']['GetServerConsole'](arg1);
+}
+
+export function GetServerCrashHistory(arg1) {
+  return window['go']['app']['App']['GetServerCrashHistory'](arg1);
+}
+
+export function GetServerMods(arg1) {
+  return window['go']['app']['App']['GetServerMods'](arg1);
+}
+
+export function GetTokenStorage() {
+  return window['go']['app']['App']['GetTokenStorage']();
+}
+
 export function GetUserProfile() {
   return window['go']['app']['App']['GetUserProfile']();
 }
@@ -82,6 +162,10 @@ export function GetUserUUID() {
   return window['go']['app']['App']['GetUserUUID']();
 }
 
+export function ImportIntegrityManifest() {
+  return window['go']['app']['App']['ImportIntegrityManifest']();
+}
+
 export function InstallMod(arg1) {
   return window['go']['app']['App']['InstallMod'](arg1);
 }
@@ -94,6 +178,10 @@ export function InstallModFileToInstance(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['InstallModFileToInstance'](arg1, arg2, arg3, arg4);
 }
 
+export function InstallModFileToTarget(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['app']['App']['InstallModFileToTarget'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function InstallModToInstance(arg1, arg2, arg3) {
   return window['go']['app']['App']['InstallModToInstance'](arg1, arg2, arg3);
 }
@@ -102,14 +190,38 @@ export function IsGameRunning() {
   return window['go']['app']['App']['IsGameRunning']();
 }
 
+export function IsPortableMode() {
+  return window['go']['app']['App']['IsPortableMode']();
+}
+
 export function Launch(arg1) {
   return window['go']['app']['App']['Launch'](arg1);
 }
 
+export function ListAccounts() {
+  return window['go']['app']['App']['ListAccounts']();
+}
+
+export function ListGameLogs() {
+  return window['go']['app']['App']['ListGameLogs']();
+}
+
+export function ListServerBackups(arg1) {
+  return window['go']['app']['App']['ListServerBackups'](arg1);
+}
+
+export function ListServers() {
+  return window['go']['app']['App']['ListServers']();
+}
+
 export function LoginWithHytaleAccount() {
   return window['go']['app']['App']['LoginWithHytaleAccount']();
 }
 
+export function LoginWithHytaleAccountManual() {
+  return window['go']['app']['App']['LoginWithHytaleAccountManual']();
+}
+
 export function LogoutHytaleAccount() {
   return window['go']['app']['App']['LogoutHytaleAccount']();
 }
@@ -130,10 +242,30 @@ export function OpenModsFolder() {
   return window['go']['app']['App']['OpenModsFolder']();
 }
 
+export function OpenServerFolder(arg1) {
+  return window['go']['app']['App']['OpenServerFolder'](arg1);
+}
+
+export function PreviewServerModSync(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['PreviewServerModSync'](arg1, arg2, arg3, arg4);
+}
+
 export function QuickLaunch() {
   return window['go']['app']['App']['QuickLaunch']();
 }
 
+export function RemoveAccount(arg1) {
+  return window['go']['app']['App']['RemoveAccount'](arg1);
+}
+
+export function RestoreOriginalGameFiles() {
+  return window['go']['app']['App']['RestoreOriginalGameFiles']();
+}
+
+export function RestoreServerBackup(arg1, arg2) {
+  return window['go']['app']['App']['RestoreServerBackup'](arg1, arg2);
+}
+
 export function RunDiagnostics() {
   return window['go']['app']['App']['RunDiagnostics']();
 }
@@ -158,10 +290,54 @@ export function SelectInstanceDirectory() {
   return window['go']['app']['App']['SelectInstanceDirectory']();
 }
 
+export function SelectProfile(arg1) {
+  return window['go']['app']['App']['SelectProfile'](arg1);
+}
+
+export function SendServerCommand(arg1, arg2) {
+  return window['go']['app']['App']['SendServerCommand'](arg1, arg2);
+}
+
+export function SetDisplayBackend(arg1) {
+  return window['go']['app']['App']['SetDisplayBackend'](arg1);
+}
+
+export function SetGameBuild(arg1, arg2) {
+  return window['go']['app']['App']['SetGameBuild'](arg1, arg2);
+}
+
+export function SetLaunchOptions(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SetLaunchOptions'](arg1, arg2, arg3);
+}
+
 export function SetMusicEnabled(arg1) {
   return window['go']['app']['App']['SetMusicEnabled'](arg1);
 }
 
+export function SetTokenPassphrase(arg1) {
+  return window['go']['app']['App']['SetTokenPassphrase'](arg1);
+}
+
+export function StartServer(arg1) {
+  return window['go']['app']['App']['StartServer'](arg1);
+}
+
+export function StopServer(arg1, arg2) {
+  return window['go']['app']['App']['StopServer'](arg1, arg2);
+}
+
+export function SubmitLoginCode(arg1) {
+  return window['go']['app']['App']['SubmitLoginCode'](arg1);
+}
+
+export function SwitchAccount(arg1) {
+  return window['go']['app']['App']['SwitchAccount'](arg1);
+}
+
+export function SyncServerMods(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['SyncServerMods'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function ToggleInstanceMod(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['ToggleInstanceMod'](arg1, arg2, arg3, arg4);
 }
@@ -178,6 +354,38 @@ export function UninstallMod(arg1) {
   return window['go']['app']['App']['UninstallMod'](arg1);
 }
 
+export function UninstallServerMod(arg1, arg2) {
+  return window['go']['app']['App']['UninstallServerMod'](arg1, arg2);
+}
+
+export function UnlockTokenStorage(arg1) {
+  return window['go']['app']['App']['UnlockTokenStorage'](arg1);
+}
+
 export function Update() {
   return window['go']['app']['App']['Update']();
 }
+
+export function UpdateServerBackupSettings(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['app']['App']['UpdateServerBackupSettings'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function UpdateServerJar(arg1) {
+  return window['go']['app']['App']['UpdateServerJar'](arg1);
+}
+
+export function UpdateServerSettings(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['UpdateServerSettings'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function UpdateServerWatchdogSettings(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['UpdateServerWatchdogSettings'](arg1, arg2, arg3, arg4);
+}
+
+export function UseGameInstall(arg1) {
+  return window['go']['app']['App']['UseGameInstall'](arg1);
+}
+
+export function VerifyGameInstall() {
+  return window['go']['app']['App']['VerifyGameInstall']();
+}
@@ -1,5 +1,23 @@
 export namespace app {
 	
+	export class AppError {
+	    type: string;
+	    message: string;
+	    technical?: string;
+	    timestamp: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.message = source["message"];
+	        this.technical = source["technical"];
+	        this.timestamp = source["timestamp"];
+	    }
+	}
 	export class ConnectivityInfo {
 	    hytalePatches: boolean;
 	    github: boolean;
@@ -121,15 +139,194 @@ export namespace app {
 		}
 	}
 	
+	
+	export class ServerStatus {
+	    settings: server.Settings;
+	    dir: string;
+	    running: boolean;
+	    process?: server.ProcessInfo;
+	    restartPending: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.settings = this.convertValues(source["settings"], server.Settings);
+	        this.dir = source["dir"];
+	        this.running = source["running"];
+	        this.process = this.convertValues(source["process"], server.ProcessInfo);
+	        this.restartPending = source["restartPending"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace auth {
+	
+	export class Profile {
+	    uuid: string;
+	    username: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.uuid = source["uuid"];
+	        this.username = source["username"];
+	    }
+	}
+	export class AccountInfo {
+	    key: string;
+	    username: string;
+	    uuid: string;
+	    accountOwnerId: string;
+	    expiresAt: any;
+	    active: boolean;
+	    profiles: Profile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.username = source["username"];
+	        this.uuid = source["uuid"];
+	        this.accountOwnerId = source["accountOwnerId"];
+	        this.expiresAt = this.convertValues(source["expiresAt"], any);
+	        this.active = source["active"];
+	        this.profiles = this.convertValues(source["profiles"], Profile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LogoutStep {
+	    name: string;
+	    ok: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogoutStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.ok = source["ok"];
+	        this.error = source["error"];
+	    }
+	}
+	export class LogoutReport {
+	    key: string;
+	    username: string;
+	    uuid: string;
+	    accountOwnerId: string;
+	    steps: LogoutStep[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LogoutReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.username = source["username"];
+	        this.uuid = source["uuid"];
+	        this.accountOwnerId = source["accountOwnerId"];
+	        this.steps = this.convertValues(source["steps"], LogoutStep);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class TokenStorageInfo {
+	    store: string;
+	    passphraseProtected: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TokenStorageInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.store = source["store"];
+	        this.passphraseProtected = source["passphraseProtected"];
+	        this.locked = source["locked"];
+	    }
+	}
 
 }
 
 export namespace config {
 	
 	export class Config {
+	    schemaVersion: number;
 	    version: string;
 	    musicEnabled: boolean;
 	    gameInstallPath: string;
+	    launchArgs: string;
+	    launchEnv: Record<string, string>;
+	    launchWrapper: string;
+	    displayBackend: string;
+	    patchline: string;
+	    gameBuild: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -137,9 +334,220 @@ export namespace config {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schemaVersion = source["schemaVersion"];
 	        this.version = source["version"];
 	        this.musicEnabled = source["musicEnabled"];
 	        this.gameInstallPath = source["gameInstallPath"];
+	        this.launchArgs = source["launchArgs"];
+	        this.launchEnv = source["launchEnv"];
+	        this.launchWrapper = source["launchWrapper"];
+	        this.displayBackend = source["displayBackend"];
+	        this.patchline = source["patchline"];
+	        this.gameBuild = source["gameBuild"];
+	    }
+	}
+	export class Setting {
+	    key: string;
+	    value: string;
+	    source: string;
+	    envVar: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Setting(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.source = source["source"];
+	        this.envVar = source["envVar"];
+	    }
+	}
+
+}
+
+export namespace game {
+	
+	export class GameLogInfo {
+	    sessionId: string;
+	    path: string;
+	    size: number;
+	    updatedAt: string;
+	    running: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GameLogInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sessionId = source["sessionId"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.updatedAt = source["updatedAt"];
+	        this.running = source["running"];
+	    }
+	}
+	export class Patchline {
+	    name: string;
+	    builds: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Patchline(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.builds = source["builds"];
+	    }
+	}
+	export class InstallCandidate {
+	    path: string;
+	    source: string;
+	    patchlines: Patchline[];
+	
+	    static createFrom(source: any = {}) {
+	        return new InstallCandidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.source = source["source"];
+	        this.patchlines = this.convertValues(source["patchlines"], Patchline);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IntegrityIssue {
+	    path: string;
+	    problem: string;
+	    restorable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new IntegrityIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.problem = source["problem"];
+	        this.restorable = source["restorable"];
+	    }
+	}
+	export class IntegrityReport {
+	    patchline: string;
+	    build: string;
+	    manifestSource: string;
+	    checkedFiles: number;
+	    issues: IntegrityIssue[];
+	    ok: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new IntegrityReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.patchline = source["patchline"];
+	        this.build = source["build"];
+	        this.manifestSource = source["manifestSource"];
+	        this.checkedFiles = source["checkedFiles"];
+	        this.issues = this.convertValues(source["issues"], IntegrityIssue);
+	        this.ok = source["ok"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LaunchCommand {
+	    path: string;
+	    args: string[];
+	    dir: string;
+	    env: string[];
+	    clientPath: string;
+	    userDataDir: string;
+	    patchline: string;
+	    build: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LaunchCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.args = source["args"];
+	        this.dir = source["dir"];
+	        this.env = source["env"];
+	        this.clientPath = source["clientPath"];
+	        this.userDataDir = source["userDataDir"];
+	        this.patchline = source["patchline"];
+	        this.build = source["build"];
+	    }
+	}
+	
+	export class SessionInfo {
+	    id: string;
+	    pid: number;
+	    startedAt: string;
+	    endedAt?: string;
+	    running: boolean;
+	    exitCode: number;
+	    exitSignal?: string;
+	    error?: string;
+	    logFile?: string;
+	    crashReport?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.pid = source["pid"];
+	        this.startedAt = source["startedAt"];
+	        this.endedAt = source["endedAt"];
+	        this.running = source["running"];
+	        this.exitCode = source["exitCode"];
+	        this.exitSignal = source["exitSignal"];
+	        this.error = source["error"];
+	        this.logFile = source["logFile"];
+	        this.crashReport = source["crashReport"];
 	    }
 	}
 
@@ -400,6 +808,68 @@ export namespace mods {
 		    return a;
 		}
 	}
+	export class SyncAction {
+	    modId: string;
+	    name: string;
+	    fileName: string;
+	    fromVersion?: string;
+	    toVersion?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncAction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.modId = source["modId"];
+	        this.name = source["name"];
+	        this.fileName = source["fileName"];
+	        this.fromVersion = source["fromVersion"];
+	        this.toVersion = source["toVersion"];
+	    }
+	}
+	export class SyncPlan {
+	    add: SyncAction[];
+	    update: SyncAction[];
+	    remove: SyncAction[];
+	    unchanged: string[];
+	    unmanaged: string[];
+	    conflicts: SyncAction[];
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.add = this.convertValues(source["add"], SyncAction);
+	        this.update = this.convertValues(source["update"], SyncAction);
+	        this.remove = this.convertValues(source["remove"], SyncAction);
+	        this.unchanged = source["unchanged"];
+	        this.unmanaged = source["unmanaged"];
+	        this.conflicts = this.convertValues(source["conflicts"], SyncAction);
+	        this.hash = source["hash"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -465,6 +935,146 @@ export namespace news {
 		    return a;
 		}
 	}
+	
+
+}
+
+export namespace server {
+	
+	export class BackupInfo {
+	    id: string;
+	    server: string;
+	    reason: string;
+	    createdAt: string;
+	    size: number;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.server = source["server"];
+	        this.reason = source["reason"];
+	        this.createdAt = source["createdAt"];
+	        this.size = source["size"];
+	        this.path = source["path"];
+	    }
+	}
+	export class ConsoleLine {
+	    server: string;
+	    stream: string;
+	    line: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConsoleLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server = source["server"];
+	        this.stream = source["stream"];
+	        this.line = source["line"];
+	    }
+	}
+	export class CrashRecord {
+	    server: string;
+	    pid: number;
+	    exitCode: number;
+	    startedAt: string;
+	    endedAt: string;
+	    logTail: string[];
+	    restartDelay?: string;
+	    gaveUp: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CrashRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server = source["server"];
+	        this.pid = source["pid"];
+	        this.exitCode = source["exitCode"];
+	        this.startedAt = source["startedAt"];
+	        this.endedAt = source["endedAt"];
+	        this.logTail = source["logTail"];
+	        this.restartDelay = source["restartDelay"];
+	        this.gaveUp = source["gaveUp"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ProcessInfo {
+	    server: string;
+	    pid: number;
+	    startedAt: string;
+	    endedAt?: string;
+	    running: boolean;
+	    exitCode: number;
+	    stopped: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server = source["server"];
+	        this.pid = source["pid"];
+	        this.startedAt = source["startedAt"];
+	        this.endedAt = source["endedAt"];
+	        this.running = source["running"];
+	        this.exitCode = source["exitCode"];
+	        this.stopped = source["stopped"];
+	    }
+	}
+	export class Settings {
+	    name: string;
+	    patchline: string;
+	    build: string;
+	    assetsPath: string;
+	    minHeap: string;
+	    maxHeap: string;
+	    jvmArgs: string;
+	    serverArgs: string;
+	    createdAt: string;
+	    backupIntervalMinutes: number;
+	    backupOnModChange: boolean;
+	    keepLastBackups: number;
+	    keepDailyBackups: number;
+	    keepWeeklyBackups: number;
+	    autoRestart: boolean;
+	    maxCrashes: number;
+	    crashWindowMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.patchline = source["patchline"];
+	        this.build = source["build"];
+	        this.assetsPath = source["assetsPath"];
+	        this.minHeap = source["minHeap"];
+	        this.maxHeap = source["maxHeap"];
+	        this.jvmArgs = source["jvmArgs"];
+	        this.serverArgs = source["serverArgs"];
+	        this.createdAt = source["createdAt"];
+	        this.backupIntervalMinutes = source["backupIntervalMinutes"];
+	        this.backupOnModChange = source["backupOnModChange"];
+	        this.keepLastBackups = source["keepLastBackups"];
+	        this.keepDailyBackups = source["keepDailyBackups"];
+	        this.keepWeeklyBackups = source["keepWeeklyBackups"];
+	        this.autoRestart = source["autoRestart"];
+	        this.maxCrashes = source["maxCrashes"];
+	        this.crashWindowMinutes = source["crashWindowMinutes"];
+	    }
+	}
 
 }
 
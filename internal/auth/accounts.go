@@ -0,0 +1,381 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/util"
+)
+
+// AccountStore holds every signed-in account and which one is active
+type AccountStore struct {
+	Active   string                  `json:"active"`
+	Accounts map[string]*AuthSession `json:"accounts"`
+	// SelectedProfiles maps account owners to the profile UUID chosen to play as
+	SelectedProfiles map[string]string `json:"selected_profiles,omitempty"`
+}
+
+// AccountInfo describes a signed-in account without its tokens
+type AccountInfo struct {
+	Key            string    `json:"key"`
+	Username       string    `json:"username"`
+	UUID           string    `json:"uuid"`
+	AccountOwnerID string    `json:"accountOwnerId"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	Active         bool      `json:"active"`
+	Profiles       []Profile `json:"profiles"`
+}
+
+// accountsMu serializes read-modify-write cycles of the account store
+var accountsMu sync.Mutex
+
+// AccountKey returns the key of an account: its owner and profile UUID
+func AccountKey(accountOwnerID, uuid string) string {
+	return accountOwnerID + ":" + uuid
+}
+
+// Key returns the account key of a session
+func (s *AuthSession) Key() string {
+	return AccountKey(s.AccountOwnerID, s.UUID)
+}
+
+// GetAccountsPath returns the path of the account store
+func GetAccountsPath() string {
+	return filepath.Join(env.GetDefaultAppDir(), "accounts.json")
+}
+
+// loadAccounts reads the account store, importing a legacy single-session file if present
+func loadAccounts() (*AccountStore, error) {
+	store := &AccountStore{Accounts: map[string]*AuthSession{}}
+
+	data, err := util.ReadFileWithBackup(GetAccountsPath(), func(data []byte) error {
+		return json.Unmarshal(data, &AccountStore{})
+	})
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read accounts file: %w", err)
+		}
+		if err := migrateLegacySession(store); err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts file: %w", err)
+	}
+	if store.Accounts == nil {
+		store.Accounts = map[string]*AuthSession{}
+	}
+
+	// Tokens used to be stored in plain text; move them to the secret store
+	for _, session := range store.Accounts {
+		if session.hasTokens() {
+			if err := saveAccounts(store); err != nil {
+				fmt.Printf("Warning: failed to move saved tokens to the %s: %v\n", TokenStoreName(), err)
+			} else {
+				fmt.Printf("Moved saved tokens to the %s\n", TokenStoreName())
+				// The backup is the previous file and still holds the plain text tokens
+				if err := util.RemoveBackup(GetAccountsPath()); err != nil {
+					fmt.Printf("Warning: failed to remove the old account backup: %v\n", err)
+				}
+			}
+			break
+		}
+	}
+	return store, nil
+}
+
+// migrateLegacySession moves a session.json from before multi-account support into the store
+func migrateLegacySession(store *AccountStore) error {
+	data, err := os.ReadFile(GetSessionPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var session AuthSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	store.Accounts[session.Key()] = &session
+	store.Active = session.Key()
+	if err := saveAccounts(store); err != nil {
+		return err
+	}
+
+	os.Remove(GetSessionPath())
+	fmt.Printf("Migrated saved session of %s to the account store\n", session.Username)
+	return nil
+}
+
+// saveAccounts writes the account store. Tokens of sessions that hold them are saved to the
+// secret store; the file itself never contains tokens.
+func saveAccounts(store *AccountStore) error {
+	stripped := *store
+	stripped.Accounts = make(map[string]*AuthSession, len(store.Accounts))
+	for key, session := range store.Accounts {
+		if session.hasTokens() {
+			if err := storeTokens(key, session); err != nil {
+				return err
+			}
+		}
+		stripped.Accounts[key] = session.withoutTokens()
+	}
+
+	data, err := json.MarshalIndent(&stripped, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal accounts: %w", err)
+	}
+
+	path := GetAccountsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create accounts directory: %w", err)
+	}
+
+	if err := util.WriteFileWithBackup(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	return nil
+}
+
+// ListAccounts returns every signed-in account, ordered by username
+func ListAccounts() ([]AccountInfo, error) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]AccountInfo, 0, len(store.Accounts))
+	for key, session := range store.Accounts {
+		accounts = append(accounts, AccountInfo{
+			Key:            key,
+			Username:       session.Username,
+			UUID:           session.UUID,
+			AccountOwnerID: session.AccountOwnerID,
+			ExpiresAt:      session.ExpiresAt,
+			Active:         key == store.Active,
+			Profiles:       session.Profiles,
+		})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Username != accounts[j].Username {
+			return accounts[i].Username < accounts[j].Username
+		}
+		return accounts[i].Key < accounts[j].Key
+	})
+	return accounts, nil
+}
+
+// SwitchAccount makes a signed-in account the active one
+func SwitchAccount(key string) error {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return err
+	}
+
+	session, ok := store.Accounts[key]
+	if !ok {
+		return fmt.Errorf("account not found: %s", key)
+	}
+
+	store.Active = key
+	if err := saveAccounts(store); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to account %s (UUID: %s)\n", session.Username, session.UUID)
+	return nil
+}
+
+// RemoveAccount removes a signed-in account and its tokens. If it was active, another account becomes active.
+func RemoveAccount(key string) error {
+	if _, err := removeAccount(key); err != nil {
+		return err
+	}
+
+	if err := deleteTokens(key); err != nil {
+		fmt.Printf("Warning: failed to delete tokens of %s: %v\n", key, err)
+	}
+	return nil
+}
+
+// removeAccount removes an account from the store, along with the profile choice of its
+// owner once no other account of that owner is left. It returns the removed session.
+func removeAccount(key string) (*AuthSession, error) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	session, ok := store.Accounts[key]
+	if !ok {
+		return nil, fmt.Errorf("account not found: %s", key)
+	}
+
+	delete(store.Accounts, key)
+	if store.Active == key {
+		store.Active = firstAccountKey(store)
+	}
+
+	ownerLeft := false
+	for _, other := range store.Accounts {
+		if other.AccountOwnerID == session.AccountOwnerID {
+			ownerLeft = true
+		}
+	}
+	if !ownerLeft {
+		delete(store.SelectedProfiles, session.AccountOwnerID)
+	}
+
+	if err := saveAccounts(store); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// firstAccountKey returns the smallest account key, or "" if the store is empty
+func firstAccountKey(store *AccountStore) string {
+	keys := make([]string, 0, len(store.Accounts))
+	for key := range store.Accounts {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// selectedProfile returns the profile UUID chosen for an account owner, or "" if none was chosen
+func selectedProfile(accountOwnerID string) string {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return ""
+	}
+	return store.SelectedProfiles[accountOwnerID]
+}
+
+// SelectProfile switches the active account to another game profile of the same owner
+// and remembers the choice for future logins. Game session tokens are issued per profile,
+// so new ones are created at the next launch.
+func SelectProfile(uuid string) (*AuthSession, error) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	session, ok := store.Accounts[store.Active]
+	if !ok {
+		return nil, fmt.Errorf("no session found - please login")
+	}
+
+	var profile *Profile
+	for i := range session.Profiles {
+		if session.Profiles[i].UUID == uuid {
+			profile = &session.Profiles[i]
+		}
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s does not belong to this account", uuid)
+	}
+
+	oldKey := session.Key()
+	renamed := false
+	if existing, ok := store.Accounts[AccountKey(session.AccountOwnerID, profile.UUID)]; ok && existing != session {
+		// The profile is already signed in as its own account; switch to it rather than replacing it
+		if err := loadTokens(existing.Key(), existing); err != nil {
+			return nil, err
+		}
+		session = existing
+		store.Active = session.Key()
+	} else if profile.UUID != session.UUID {
+		if err := loadTokens(oldKey, session); err != nil {
+			return nil, err
+		}
+		delete(store.Accounts, oldKey)
+		session.UUID = profile.UUID
+		session.Username = profile.Username
+		session.SessionToken = ""
+		session.IdentityToken = ""
+		store.Accounts[session.Key()] = session
+		store.Active = session.Key()
+		renamed = true
+	}
+
+	if store.SelectedProfiles == nil {
+		store.SelectedProfiles = map[string]string{}
+	}
+	store.SelectedProfiles[session.AccountOwnerID] = profile.UUID
+
+	if err := saveAccounts(store); err != nil {
+		return nil, err
+	}
+	if renamed {
+		if err := deleteTokens(oldKey); err != nil {
+			fmt.Printf("Warning: failed to delete tokens of %s: %v\n", oldKey, err)
+		}
+	}
+
+	fmt.Printf("Playing as profile %s (UUID: %s)\n", session.Username, session.UUID)
+	return session, nil
+}
+
+// RefreshProfiles fetches the profile list of the active account and stores it
+func RefreshProfiles() ([]Profile, error) {
+	session, err := LoadSession()
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("no session found - please login")
+	}
+	if session.IsExpired() {
+		return session.Profiles, nil
+	}
+
+	profiles, err := fetchProfiles(session.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profiles: %w", err)
+	}
+
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return nil, err
+	}
+	if current, ok := store.Accounts[session.Key()]; ok {
+		current.Profiles = profiles.Profiles
+		if err := saveAccounts(store); err != nil {
+			return nil, err
+		}
+	}
+	return profiles.Profiles, nil
+}
@@ -6,12 +6,12 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -29,14 +29,18 @@ const (
 )
 
 type AuthSession struct {
-	AccessToken    string    `json:"access_token"`
-	RefreshToken   string    `json:"refresh_token"`
+	AccessToken    string    `json:"access_token,omitempty"`
+	RefreshToken   string    `json:"refresh_token,omitempty"`
 	ExpiresAt      time.Time `json:"expires_at"`
-	SessionToken   string    `json:"session_token"`
-	IdentityToken  string    `json:"identity_token"`
+	SessionToken   string    `json:"session_token,omitempty"`
+	IdentityToken  string    `json:"identity_token,omitempty"`
 	Username       string    `json:"username"`
 	UUID           string    `json:"uuid"`
 	AccountOwnerID string    `json:"account_owner_id"`
+	Profiles       []Profile `json:"profiles,omitempty"` // every game profile of the account
+
+	// GameSessionExpiresAt is when SessionToken and IdentityToken expire; ExpiresAt is the OAuth expiry
+	GameSessionExpiresAt time.Time `json:"game_session_expires_at,omitempty"`
 }
 
 type Profile struct {
@@ -59,51 +63,90 @@ func (s *AuthSession) IsExpired() bool {
 	return time.Now().UTC().After(s.ExpiresAt)
 }
 
+// hasGameSession reports whether the session holds game tokens that are still valid for margin
+func (s *AuthSession) hasGameSession(margin time.Duration) bool {
+	return s.SessionToken != "" && time.Now().Add(margin).Before(s.GameSessionExpiresAt)
+}
+
+// GetSessionPath returns the single-session file used before multi-account support
 func GetSessionPath() string {
 	return filepath.Join(env.GetDefaultAppDir(), "session.json")
 }
 
+// SaveSession stores a session in the account store and makes it the active account
 func SaveSession(session *AuthSession) error {
-	data, err := json.MarshalIndent(session, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal session: %w", err)
-	}
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
 
-	sessionPath := GetSessionPath()
-	if err := os.MkdirAll(filepath.Dir(sessionPath), 0700); err != nil {
-		return fmt.Errorf("failed to create session directory: %w", err)
+	store, err := loadAccounts()
+	if err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(sessionPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write session file: %w", err)
+	store.Accounts[session.Key()] = session
+	store.Active = session.Key()
+	if err := saveAccounts(store); err != nil {
+		return err
 	}
 
 	fmt.Printf("Session saved for user: %s (UUID: %s)\n", session.Username, session.UUID)
 	return nil
 }
 
-func LoadSession() (*AuthSession, error) {
-	sessionPath := GetSessionPath()
-	data, err := os.ReadFile(sessionPath)
+// updateActiveSession stores renewed tokens of the active account without changing which
+// account is active. It stores nothing and returns false if the session's account is no
+// longer the active one, e.g. because the user switched accounts during a refresh.
+func updateActiveSession(session *AuthSession) (bool, error) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to read session file: %w", err)
+		return false, err
+	}
+	if store.Active != session.Key() {
+		return false, nil
 	}
 
-	var session AuthSession
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to parse session: %w", err)
+	store.Accounts[session.Key()] = session
+	if err := saveAccounts(store); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadSession returns the session of the active account, or nil if no account is signed in
+func LoadSession() (*AuthSession, error) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	store, err := loadAccounts()
+	if err != nil {
+		return nil, err
 	}
 
-	return &session, nil
+	session, ok := store.Accounts[store.Active]
+	if !ok {
+		return nil, nil
+	}
+	if err := loadTokens(store.Active, session); err != nil {
+		return nil, err
+	}
+	return session, nil
 }
 
+// ClearSession signs out the active account
 func ClearSession() error {
-	sessionPath := GetSessionPath()
-	if err := os.Remove(sessionPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove session file: %w", err)
+	session, err := LoadSession()
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+
+	if err := RemoveAccount(session.Key()); err != nil {
+		return err
 	}
 	fmt.Println("Session cleared")
 	return nil
@@ -143,8 +186,8 @@ func RefreshSession(session *AuthSession) (*AuthSession, error) {
 	}
 
 	var tokenResp struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
+		AccessToken  string `json:"access_token,omitempty"`
+		RefreshToken string `json:"refresh_token,omitempty"`
 		ExpiresIn    int    `json:"expires_in"`
 	}
 
@@ -159,6 +202,7 @@ func RefreshSession(session *AuthSession) (*AuthSession, error) {
 		Username:       session.Username,
 		UUID:           session.UUID,
 		AccountOwnerID: session.AccountOwnerID,
+		Profiles:       session.Profiles,
 	}
 
 	if err := createGameSession(newSession); err != nil {
@@ -168,6 +212,9 @@ func RefreshSession(session *AuthSession) (*AuthSession, error) {
 	return newSession, nil
 }
 
+// gameSessionMargin is how long game session tokens must stay valid to be reused
+const gameSessionMargin = 2 * time.Minute
+
 // GetValidSession returns a valid session, refreshing if necessary
 func GetValidSession() (*AuthSession, error) {
 	session, err := LoadSession()
@@ -179,30 +226,36 @@ func GetValidSession() (*AuthSession, error) {
 		return nil, fmt.Errorf("no session found - please login")
 	}
 
+	return renewSession(session)
+}
+
+// renewSession refreshes expired tokens and game session tokens that are about to expire,
+// and stores the result. A failed refresh keeps the account, so that a network error does
+// not sign the user out.
+func renewSession(session *AuthSession) (*AuthSession, error) {
 	// If session is expired, try to refresh
 	if session.IsExpired() {
 		fmt.Println("Session expired, attempting to refresh...")
 		newSession, err := RefreshSession(session)
 		if err != nil {
-			ClearSession()
-			return nil, fmt.Errorf("session expired and refresh failed - please login again")
+			return nil, fmt.Errorf("session expired and refresh failed - please login again: %w", err)
 		}
-
-		if err := SaveSession(newSession); err != nil {
-			return nil, err
+		session = newSession
+	} else if session.hasGameSession(gameSessionMargin) {
+		// Game session tokens are reused until they are about to expire
+		return session, nil
+	} else {
+		fmt.Println("Refreshing game session tokens...")
+		renewed := *session
+		if err := createGameSession(&renewed); err != nil {
+			return nil, fmt.Errorf("failed to create game session: %w", err)
 		}
-
-		return newSession, nil
+		session = &renewed
 	}
 
-	fmt.Println("Refreshing game session tokens...")
-	if err := createGameSession(session); err != nil {
-		return nil, fmt.Errorf("failed to create game session: %w", err)
-	}
-	if err := SaveSession(session); err != nil {
+	if _, err := updateActiveSession(session); err != nil {
 		return nil, err
 	}
-
 	return session, nil
 }
 
@@ -294,15 +347,13 @@ func createGameSession(session *AuthSession) error {
 	session.SessionToken = gameSession.SessionToken
 	session.IdentityToken = gameSession.IdentityToken
 
-	// Parse expiry time
+	// Parse expiry time; game tokens are not used past the OAuth token expiry
+	session.GameSessionExpiresAt = session.ExpiresAt
 	expiresAt, err := time.Parse(time.RFC3339, gameSession.ExpiresAt)
 	if err != nil {
 		fmt.Printf("Warning: failed to parse game session expiry: %v\n", err)
-	} else {
-		// Use the earlier of OAuth token expiry or game session expiry
-		if expiresAt.Before(session.ExpiresAt) {
-			session.ExpiresAt = expiresAt
-		}
+	} else if expiresAt.Before(session.ExpiresAt) {
+		session.GameSessionExpiresAt = expiresAt
 	}
 
 	return nil
@@ -338,8 +389,14 @@ func generateCodeChallenge(verifier string) string {
 	return base64.RawURLEncoding.EncodeToString(h[:])
 }
 
-// LoginWithAuthCodeFlow initiates the OAuth authorization code flow with PKCE
-func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message string), openBrowser func(string) error) (*AuthSession, error) {
+// ErrLoginCancelled is returned when the login was cancelled before the user authorized it
+var ErrLoginCancelled = errors.New("login cancelled")
+
+// LoginWithAuthCodeFlow initiates the OAuth authorization code flow with PKCE.
+// The code arrives through the local callback server or, if input is not nil, pasted by the user.
+func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message string), openBrowser func(string) error, input *CodeInput) (*AuthSession, error) {
+	defer input.close()
+
 	// Generate PKCE verifier and challenge
 	codeVerifier, err := generateCodeVerifier()
 	if err != nil {
@@ -348,9 +405,6 @@ func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message st
 	codeChallenge := generateCodeChallenge(codeVerifier)
 
 	// Start local callback server on random available port
-	codeChan := make(chan string, 1)
-	errChan := make(chan error, 1)
-
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		return nil, fmt.Errorf("failed to start callback server: %w", err)
@@ -361,35 +415,6 @@ func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message st
 	port := listener.Addr().(*net.TCPAddr).Port
 	fmt.Printf("OAuth callback server listening on port %d\n", port)
 
-	// HTTP server for OAuth callback
-	mux := http.NewServeMux()
-	mux.HandleFunc("/authorization-callback", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		error := r.URL.Query().Get("error")
-
-		if error != "" {
-			errChan <- fmt.Errorf("OAuth error: %s", error)
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "<html><body><h1>Authentication failed</h1><p>Error: %s</p><p>You can close this window.</p></body></html>", error)
-			return
-		}
-
-		if code == "" {
-			errChan <- fmt.Errorf("no authorization code received")
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprint(w, "<html><body><h1>Authentication failed</h1><p>No code received.</p><p>You can close this window.</p></body></html>")
-			return
-		}
-
-		codeChan <- code
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, "<html><body><h1>Authentication successful!</h1><p>You can close this window and return to HyPrism.</p></body></html>")
-	})
-
-	server := &http.Server{Handler: mux}
-	go server.Serve(listener)
-	defer server.Shutdown(context.Background())
-
 	// Build authorization URL matching official launcher
 	// State contains JSON with random state and port number, base64 encoded
 	stateData := map[string]string{
@@ -399,6 +424,10 @@ func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message st
 	stateJSON, _ := json.Marshal(stateData)
 	stateParam := base64.StdEncoding.EncodeToString(stateJSON)
 
+	// HTTP server for OAuth callback; it only accepts the state built above
+	callback := startCallbackServer(listener, stateParam)
+	defer callback.stop()
+
 	// Build URL with parameters in exact order as official launcher
 	// Must manually construct to preserve order (url.Values.Encode() sorts alphabetically)
 	query := fmt.Sprintf(
@@ -426,17 +455,28 @@ func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message st
 
 	// Wait for code or error
 	var authCode string
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("login cancelled")
-	case err := <-errChan:
-		return nil, err
-	case authCode = <-codeChan:
-		// Success, continue
-	case <-time.After(5 * time.Minute):
-		return nil, fmt.Errorf("authentication timed out")
+	timeout := time.After(5 * time.Minute)
+	for authCode == "" {
+		select {
+		case <-ctx.Done():
+			return nil, ErrLoginCancelled
+		case err := <-callback.errs:
+			return nil, err
+		case authCode = <-callback.codes:
+			// Success, continue
+		case pasted := <-input.receive():
+			// A rejected paste leaves the login waiting so the user can try again
+			code, err := parseAuthorizationResponse(pasted.input, stateParam)
+			pasted.result <- err
+			authCode = code
+		case <-timeout:
+			return nil, fmt.Errorf("authentication timed out")
+		}
 	}
 
+	// The code is single-use; stop accepting callbacks right away
+	callback.stop()
+
 	if progressCallback != nil {
 		progressCallback("Exchanging authorization code for token...")
 	}
@@ -462,11 +502,19 @@ func LoginWithAuthCodeFlow(ctx context.Context, progressCallback func(message st
 		return nil, fmt.Errorf("no profiles found for this account")
 	}
 
-	// Use the first profile
+	// Use the profile chosen for this account before, or the first one
 	profile := profiles.Profiles[0]
+	if uuid := selectedProfile(profiles.Owner); uuid != "" {
+		for _, p := range profiles.Profiles {
+			if p.UUID == uuid {
+				profile = p
+			}
+		}
+	}
 	session.Username = profile.Username
 	session.UUID = profile.UUID
 	session.AccountOwnerID = profiles.Owner
+	session.Profiles = profiles.Profiles
 
 	if progressCallback != nil {
 		progressCallback(fmt.Sprintf("Logged in as: %s", session.Username))
@@ -526,8 +574,8 @@ func exchangeCodeForToken(code, verifier, redirectURL string) (*AuthSession, err
 	}
 
 	var tokenResp struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
+		AccessToken  string `json:"access_token,omitempty"`
+		RefreshToken string `json:"refresh_token,omitempty"`
 		ExpiresIn    int    `json:"expires_in"`
 	}
 
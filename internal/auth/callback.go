@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// callbackPath is where the consent page redirects the browser to
+const callbackPath = "/authorization-callback"
+
+// callbackServer receives the browser redirect of a single login. Only a request carrying the
+// state of that login is accepted, and only once; anything else gets an error page and leaves
+// the login waiting.
+type callbackServer struct {
+	state  string
+	server *http.Server
+
+	mu   sync.Mutex
+	used bool
+
+	codes chan string
+	errs  chan error
+	stop  func()
+}
+
+// startCallbackServer serves the OAuth callback of a login on listener
+func startCallbackServer(listener net.Listener, state string) *callbackServer {
+	c := &callbackServer{
+		state: state,
+		codes: make(chan string, 1),
+		errs:  make(chan error, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, c.handle)
+	c.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	c.stop = sync.OnceFunc(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		c.server.Shutdown(ctx)
+	})
+
+	go c.server.Serve(listener)
+	return c
+}
+
+// handle answers a callback request
+func (c *callbackServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeCallbackPage(w, http.StatusMethodNotAllowed, "Authentication failed", "Unsupported request.")
+		return
+	}
+
+	query := r.URL.Query()
+	if err := checkState(query.Get("state"), c.state); err != nil {
+		fmt.Printf("Ignored OAuth callback: %v\n", err)
+		writeCallbackPage(w, http.StatusBadRequest, "Authentication failed",
+			"This sign-in response does not belong to the login HyPrism is waiting for. Start the login again from HyPrism.")
+		return
+	}
+
+	c.mu.Lock()
+	replayed := c.used
+	c.used = true
+	c.mu.Unlock()
+	if replayed {
+		writeCallbackPage(w, http.StatusConflict, "Already signed in",
+			"This sign-in response was already used. You can close this window.")
+		return
+	}
+
+	if oauthErr := query.Get("error"); oauthErr != "" {
+		c.fail(fmt.Errorf("OAuth error: %s", oauthErr))
+		writeCallbackPage(w, http.StatusBadRequest, "Authentication failed",
+			"Error: "+oauthErr+". You can close this window.")
+		return
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		c.fail(fmt.Errorf("no authorization code received"))
+		writeCallbackPage(w, http.StatusBadRequest, "Authentication failed", "No code received. You can close this window.")
+		return
+	}
+
+	select {
+	case c.codes <- code:
+	default:
+	}
+	writeCallbackPage(w, http.StatusOK, "Authentication successful!", "You can close this window and return to HyPrism.")
+}
+
+// fail reports an error to the login without blocking
+func (c *callbackServer) fail(err error) {
+	select {
+	case c.errs <- err:
+	default:
+	}
+}
+
+// writeCallbackPage writes a minimal HTML page shown in the browser after the redirect
+func writeCallbackPage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<html><body><h1>%s</h1><p>%s</p></body></html>", html.EscapeString(title), html.EscapeString(message))
+}
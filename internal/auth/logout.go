@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"HyPrism/internal/util"
+)
+
+// RevokeURL is the OAuth token revocation endpoint (RFC 7009)
+const RevokeURL = "https://oauth.accounts.hytale.com/oauth2/revoke"
+
+// LogoutStep is the outcome of one step of a sign-out
+type LogoutStep struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// LogoutReport lists what a sign-out did. Local data is removed even if revoking the tokens failed.
+type LogoutReport struct {
+	Key            string       `json:"key"`
+	Username       string       `json:"username"`
+	UUID           string       `json:"uuid"`
+	AccountOwnerID string       `json:"accountOwnerId"`
+	Steps          []LogoutStep `json:"steps"`
+}
+
+// Record adds the outcome of a step to the report
+func (r *LogoutReport) Record(name string, err error) {
+	step := LogoutStep{Name: name, OK: err == nil}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	r.Steps = append(r.Steps, step)
+}
+
+// OK reports whether every step succeeded
+func (r *LogoutReport) OK() bool {
+	for _, step := range r.Steps {
+		if !step.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// SignOut revokes the tokens of an account at the OAuth server and removes the account,
+// its tokens and its profile choice. Revocation failures, e.g. when offline, do not stop
+// the local cleanup; the tokens then stay valid until they expire.
+func SignOut(key string) (*LogoutReport, error) {
+	accountsMu.Lock()
+	store, err := loadAccounts()
+	if err != nil {
+		accountsMu.Unlock()
+		return nil, err
+	}
+	session, ok := store.Accounts[key]
+	if !ok {
+		accountsMu.Unlock()
+		return nil, fmt.Errorf("account not found: %s", key)
+	}
+	tokensErr := loadTokens(key, session)
+	accountsMu.Unlock()
+
+	report := &LogoutReport{
+		Key:            key,
+		Username:       session.Username,
+		UUID:           session.UUID,
+		AccountOwnerID: session.AccountOwnerID,
+	}
+
+	if tokensErr != nil {
+		report.Record("Revoke tokens", fmt.Errorf("saved tokens could not be read: %w", tokensErr))
+	} else {
+		if session.RefreshToken != "" {
+			report.Record("Revoke refresh token", revokeToken(session.RefreshToken, "refresh_token"))
+		}
+		if session.AccessToken != "" {
+			report.Record("Revoke access token", revokeToken(session.AccessToken, "access_token"))
+		}
+	}
+
+	report.Record("Remove saved tokens", deleteTokens(key))
+
+	// The last known good copy of the account store still lists the account
+	_, err = removeAccount(key)
+	if err == nil {
+		err = util.RemoveBackup(GetAccountsPath())
+	}
+	report.Record("Remove account and its settings", err)
+
+	return report, nil
+}
+
+// revokeToken asks the OAuth server to invalidate a token
+func revokeToken(token, tokenTypeHint string) error {
+	client := &http.Client{Timeout: 15 * time.Second}
+
+	data := url.Values{}
+	data.Set("token", token)
+	data.Set("token_type_hint", tokenTypeHint)
+	data.Set("client_id", ClientID)
+
+	req, err := http.NewRequest("POST", RevokeURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "HyPrism/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("account server unreachable, the token stays valid until it expires: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("revocation failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+package auth
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// machineID returns a stable identifier of this machine, or "" if none is found
+func machineID() string {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+		if err != nil {
+			return ""
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.Contains(line, "IOPlatformUUID") {
+				if i := strings.LastIndex(line, "="); i >= 0 {
+					return strings.Trim(strings.TrimSpace(line[i+1:]), `"`)
+				}
+			}
+		}
+		return ""
+	}
+
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
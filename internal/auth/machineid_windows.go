@@ -0,0 +1,20 @@
+//go:build windows
+
+package auth
+
+import "golang.org/x/sys/windows/registry"
+
+// machineID returns the MachineGuid Windows assigns at install time, or "" if it cannot be read
+func machineID() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Cryptography`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+
+	id, _, err := key.GetStringValue("MachineGuid")
+	if err != nil {
+		return ""
+	}
+	return id
+}
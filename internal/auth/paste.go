@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrNoPendingLogin is returned when a code is pasted while no login is waiting for one
+var ErrNoPendingLogin = errors.New("no login is waiting for a code")
+
+// CodeInput passes authorization codes or redirect URLs the user pasted to a running login.
+// It lets the login complete when the browser cannot reach the local callback server,
+// e.g. over SSH, in a sandbox without portals or when signing in on another device.
+// Each login owns its input; a code pasted before the login waits for one is kept until it does.
+type CodeInput struct {
+	ch        chan pastedCode
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// pastedCode is a pasted input and where to report whether it was accepted
+type pastedCode struct {
+	input  string
+	result chan error
+}
+
+// NewCodeInput creates an input for pasted codes
+func NewCodeInput() *CodeInput {
+	return &CodeInput{ch: make(chan pastedCode, 1), done: make(chan struct{})}
+}
+
+// Submit hands a pasted code or redirect URL to the login waiting for it.
+// It returns an error if the input is invalid or does not belong to that login.
+func (c *CodeInput) Submit(ctx context.Context, input string) error {
+	p := pastedCode{input: input, result: make(chan error, 1)}
+	select {
+	case <-c.done:
+		return ErrNoPendingLogin
+	default:
+	}
+	select {
+	case c.ch <- p:
+	default:
+		return fmt.Errorf("another pasted code is still being checked")
+	}
+
+	select {
+	case err := <-p.result:
+		return err
+	case <-c.done:
+		return ErrNoPendingLogin
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// receive returns the channel pasted codes arrive on; nil if c is nil, which never delivers
+func (c *CodeInput) receive() <-chan pastedCode {
+	if c == nil {
+		return nil
+	}
+	return c.ch
+}
+
+// close ends the login the input belongs to; pending and later pastes get ErrNoPendingLogin
+func (c *CodeInput) close() {
+	if c == nil {
+		return
+	}
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// parseAuthorizationResponse extracts the authorization code from a pasted redirect URL or
+// bare code. A redirect URL must carry the state of the login it is pasted into.
+func parseAuthorizationResponse(input, expectedState string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("nothing was pasted")
+	}
+
+	if !strings.Contains(input, "code=") && !strings.Contains(input, "error=") {
+		if strings.ContainsAny(input, " /?&=#") {
+			return "", fmt.Errorf("this does not look like an authorization code")
+		}
+		return input, nil
+	}
+
+	query := input
+	if u, err := url.Parse(input); err == nil && (u.RawQuery != "" || u.Fragment != "") {
+		query = u.RawQuery
+		if !strings.Contains(query, "code=") && !strings.Contains(query, "error=") {
+			query = u.Fragment
+		}
+	}
+	values, err := url.ParseQuery(strings.TrimPrefix(query, "?"))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse pasted address: %w", err)
+	}
+
+	if oauthErr := values.Get("error"); oauthErr != "" {
+		return "", fmt.Errorf("OAuth error: %s", oauthErr)
+	}
+	if err := checkState(values.Get("state"), expectedState); err != nil {
+		return "", err
+	}
+
+	code := values.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("no authorization code received")
+	}
+	return code, nil
+}
+
+// checkState compares the state of an authorization response with the one the login sent
+func checkState(state, expected string) error {
+	if state == "" {
+		return fmt.Errorf("the authorization response has no state")
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(expected)) != 1 {
+		return fmt.Errorf("the authorization response belongs to a different login")
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"HyPrism/internal/env"
+)
+
+// sessionTokens are the secrets of an account. They are kept out of accounts.json
+// and live in the OS secret store, or in an encrypted file if none is available.
+type sessionTokens struct {
+	AccessToken   string `json:"access_token"`
+	RefreshToken  string `json:"refresh_token"`
+	SessionToken  string `json:"session_token"`
+	IdentityToken string `json:"identity_token"`
+}
+
+// secretStore stores the tokens of accounts by account key
+type secretStore interface {
+	Name() string
+	Get(key string) ([]byte, bool, error)
+	Set(key string, secret []byte) error
+	Delete(key string) error
+}
+
+// errSecretServiceUnavailable is returned when the OS secret store cannot be used
+var errSecretServiceUnavailable = errors.New("secret service is not available")
+
+var (
+	secretServiceOnce sync.Once
+	secretService     secretStore
+)
+
+// secretStores returns the stores tokens are looked up in, preferred first
+func secretStores() []secretStore {
+	// Portable installs move between machines, so their logins must not end up in the OS store
+	if env.IsPortable() {
+		return []secretStore{tokenFile}
+	}
+
+	secretServiceOnce.Do(func() {
+		store, err := newSecretService()
+		if err != nil {
+			fmt.Printf("Secret service unavailable, storing tokens in an encrypted file: %v\n", err)
+			return
+		}
+		secretService = store
+	})
+
+	if secretService != nil {
+		return []secretStore{secretService, tokenFile}
+	}
+	return []secretStore{tokenFile}
+}
+
+// TokenStoreName returns the name of the store new tokens are saved to
+func TokenStoreName() string {
+	return secretStores()[0].Name()
+}
+
+// tokens returns the secrets of a session
+func (s *AuthSession) tokens() sessionTokens {
+	return sessionTokens{
+		AccessToken:   s.AccessToken,
+		RefreshToken:  s.RefreshToken,
+		SessionToken:  s.SessionToken,
+		IdentityToken: s.IdentityToken,
+	}
+}
+
+// hasTokens reports whether a session holds any secret
+func (s *AuthSession) hasTokens() bool {
+	return s.tokens() != sessionTokens{}
+}
+
+// withoutTokens returns a copy of a session with its secrets removed
+func (s *AuthSession) withoutTokens() *AuthSession {
+	stripped := *s
+	stripped.AccessToken = ""
+	stripped.RefreshToken = ""
+	stripped.SessionToken = ""
+	stripped.IdentityToken = ""
+	return &stripped
+}
+
+// storeTokens saves the secrets of a session under key in the preferred store, or in the
+// encrypted file if that fails, and removes older copies from the other stores
+func storeTokens(key string, s *AuthSession) error {
+	data, err := json.Marshal(s.tokens())
+	if err != nil {
+		return fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+
+	stores := secretStores()
+	used := 0
+	err = stores[used].Set(key, data)
+	if err != nil && len(stores) > 1 {
+		// The secret service can fail at any time, e.g. when its keyring is locked; the
+		// encrypted file is always available
+		used = len(stores) - 1
+		fmt.Printf("Warning: failed to store tokens in %s, using %s instead: %v\n", stores[0].Name(), stores[used].Name(), err)
+		err = stores[used].Set(key, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store tokens in %s: %w", stores[used].Name(), err)
+	}
+	for i, store := range stores {
+		if i == used {
+			continue
+		}
+		if err := store.Delete(key); err != nil {
+			fmt.Printf("Warning: failed to remove old tokens from %s: %v\n", store.Name(), err)
+		}
+	}
+	return nil
+}
+
+// loadTokens fills in the secrets of a session stored under key. A session without
+// stored tokens is left as is, so it reads as expired.
+func loadTokens(key string, s *AuthSession) error {
+	for _, store := range secretStores() {
+		data, ok, err := store.Get(key)
+		if err != nil {
+			return fmt.Errorf("failed to read tokens from %s: %w", store.Name(), err)
+		}
+		if !ok {
+			continue
+		}
+
+		var tokens sessionTokens
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			return fmt.Errorf("failed to parse tokens: %w", err)
+		}
+		s.AccessToken = tokens.AccessToken
+		s.RefreshToken = tokens.RefreshToken
+		s.SessionToken = tokens.SessionToken
+		s.IdentityToken = tokens.IdentityToken
+		return nil
+	}
+	return nil
+}
+
+// deleteTokens removes the secrets stored under key from every store
+func deleteTokens(key string) error {
+	var errs []error
+	for _, store := range secretStores() {
+		if err := store.Delete(key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,203 @@
+//go:build linux
+
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// freedesktop Secret Service D-Bus API
+const (
+	secretServiceName       = "org.freedesktop.secrets"
+	secretServicePath       = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretDefaultCollection = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	secretServiceIface      = "org.freedesktop.Secret.Service"
+	secretCollectionIface   = "org.freedesktop.Secret.Collection"
+	secretItemIface         = "org.freedesktop.Secret.Item"
+	secretPromptIface       = "org.freedesktop.Secret.Prompt"
+
+	// secretApplication tags the items HyPrism creates
+	secretApplication = "HyPrism"
+	// secretPromptTimeout is how long the user has to answer an unlock prompt
+	secretPromptTimeout = 2 * time.Minute
+)
+
+// secretValue is the Secret struct of the Secret Service API
+type secretValue struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretServiceStore stores tokens in the default collection of the Secret Service
+// (GNOME Keyring, KWallet, KeePassXC, ...)
+type secretServiceStore struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// newSecretService connects to the Secret Service on the session bus
+func newSecretService() (secretStore, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errSecretServiceUnavailable, err)
+	}
+
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceIface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errSecretServiceUnavailable, err)
+	}
+
+	return &secretServiceStore{conn: conn, session: session}, nil
+}
+
+func (s *secretServiceStore) Name() string {
+	return "secret service"
+}
+
+func (s *secretServiceStore) Get(key string) ([]byte, bool, error) {
+	item, ok, err := s.find(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var secret secretValue
+	err = s.conn.Object(secretServiceName, item).
+		Call(secretItemIface+".GetSecret", 0, s.session).
+		Store(&secret)
+	if err != nil {
+		return nil, false, err
+	}
+	return secret.Value, true, nil
+}
+
+func (s *secretServiceStore) Set(key string, value []byte) error {
+	if err := s.unlock(secretDefaultCollection); err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		secretItemIface + ".Label":      dbus.MakeVariant("HyPrism login (" + key + ")"),
+		secretItemIface + ".Attributes": dbus.MakeVariant(secretAttributes(key)),
+	}
+	secret := secretValue{
+		Session:     s.session,
+		Value:       value,
+		ContentType: "application/json",
+	}
+
+	var item, prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretDefaultCollection).
+		Call(secretCollectionIface+".CreateItem", 0, properties, secret, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+func (s *secretServiceStore) Delete(key string) error {
+	item, ok, err := s.find(key)
+	if err != nil || !ok {
+		return err
+	}
+
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemIface+".Delete", 0).Store(&prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// find looks up the item of an account, unlocking it if needed
+func (s *secretServiceStore) find(key string) (dbus.ObjectPath, bool, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceIface+".SearchItems", 0, secretAttributes(key)).
+		Store(&unlocked, &locked)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(unlocked) > 0 {
+		return unlocked[0], true, nil
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked[0]); err != nil {
+			return "", false, err
+		}
+		return locked[0], true, nil
+	}
+	return "", false, nil
+}
+
+// unlock unlocks a collection or item, prompting the user if the service asks to
+func (s *secretServiceStore) unlock(object dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceIface+".Unlock", 0, []dbus.ObjectPath{object}).
+		Store(&unlocked, &prompt)
+	if err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// prompt shows a prompt of the service and waits for the user to complete it.
+// "/" means no prompt is needed.
+func (s *secretServiceStore) prompt(prompt dbus.ObjectPath) error {
+	if prompt == "" || prompt == "/" {
+		return nil
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretPromptIface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 8)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretServiceName, prompt).Call(secretPromptIface+".Prompt", 0, "").Err; err != nil {
+		return err
+	}
+
+	timeout := time.After(secretPromptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != prompt || len(signal.Body) == 0 {
+				continue
+			}
+			if dismissed, _ := signal.Body[0].(bool); dismissed {
+				return fmt.Errorf("secret service prompt was dismissed")
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("secret service prompt timed out")
+		}
+	}
+}
+
+// secretAttributes identifies the item of an account
+func secretAttributes(key string) map[string]string {
+	return map[string]string{
+		"application": secretApplication,
+		"account":     key,
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package auth
+
+// newSecretService reports that the freedesktop Secret Service is only used on Linux
+func newSecretService() (secretStore, error) {
+	return nil, errSecretServiceUnavailable
+}
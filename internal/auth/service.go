@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Session health events emitted to the frontend
+const (
+	EventAuthExpiring  = "auth-expiring"
+	EventAuthRefreshed = "auth-refreshed"
+	EventAuthFailed    = "auth-failed"
+)
+
+const (
+	// refreshLead is how long before expiry tokens are refreshed
+	refreshLead = 5 * time.Minute
+	// refreshJitter spreads refreshes so that several launchers do not refresh at the same moment
+	refreshJitter = time.Minute
+	// refreshRetryBase is the delay before the first retry of a failed refresh; it doubles with each failure
+	refreshRetryBase = 30 * time.Second
+	// refreshRetryMax caps the retry delay
+	refreshRetryMax = 5 * time.Minute
+	// refreshMaxRetries is how often a failed refresh is retried before giving up
+	refreshMaxRetries = 5
+)
+
+// Listener receives session health events
+type Listener func(event string, data interface{})
+
+// SessionEvent describes the active session in session health events
+type SessionEvent struct {
+	Key       string    `json:"key"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Error     string    `json:"error,omitempty"`
+	Retrying  bool      `json:"retrying"`
+}
+
+// errAccountSwitched is the result of a renewal whose account stopped being the active one
+var errAccountSwitched = errors.New("the active account changed while its session was refreshed")
+
+// SessionService caches the session of the active account and refreshes its OAuth and
+// game tokens in the background before they expire
+type SessionService struct {
+	mu       sync.Mutex
+	session  *AuthSession
+	timer    *time.Timer
+	failures int
+	running  bool
+	listener Listener
+	// account changes whenever the active account may have changed, so that renewals that
+	// ran without the lock can tell that their result belongs to another account
+	account int
+	// renewal is the token refresh in progress, if any; concurrent callers wait for it
+	renewal *renewal
+}
+
+// renewal is a token refresh in progress
+type renewal struct {
+	account int
+	done    chan struct{}
+	session *AuthSession
+	err     error
+}
+
+// NewSessionService creates a session service that reports events to listener
+func NewSessionService(listener Listener) *SessionService {
+	return &SessionService{listener: listener}
+}
+
+// Start loads the active session and schedules its refresh
+func (s *SessionService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = true
+	s.reload()
+}
+
+// Stop cancels the scheduled refresh
+func (s *SessionService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.cancel()
+}
+
+// Invalidate drops the cached session, e.g. after the active account changed
+func (s *SessionService) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = nil
+	s.failures = 0
+	s.account++
+	s.renewal = nil // a running renewal belongs to the previous account
+	s.reload()
+}
+
+// Session returns a session with valid game tokens. The cached session is used until it is
+// about to expire; only then are tokens refreshed, once for all concurrent callers. A failed
+// refresh keeps the account and is reported as an auth-failed event.
+func (s *SessionService) Session() (*AuthSession, error) {
+	for attempt := 0; ; attempt++ {
+		session, err := s.currentSession()
+		// A renewal of the previous account is retried for the new one
+		if !errors.Is(err, errAccountSwitched) || attempt >= 2 {
+			return session, err
+		}
+	}
+}
+
+// currentSession returns the cached session, or waits for a renewal, starting one if needed
+func (s *SessionService) currentSession() (*AuthSession, error) {
+	s.mu.Lock()
+	if s.session != nil && !s.session.IsExpired() && s.session.hasGameSession(gameSessionMargin) {
+		session := s.session
+		s.mu.Unlock()
+		return session, nil
+	}
+	r, started := s.startRenewal()
+	s.mu.Unlock()
+
+	if started {
+		s.renew(r)
+	}
+	<-r.done
+	return r.session, r.err
+}
+
+// startRenewal returns the renewal in progress, or registers a new one that the caller must
+// run. s.mu must be held.
+func (s *SessionService) startRenewal() (*renewal, bool) {
+	if s.renewal != nil {
+		return s.renewal, false
+	}
+	s.renewal = &renewal{account: s.account, done: make(chan struct{})}
+	return s.renewal, true
+}
+
+// renew loads the active session and refreshes its tokens if needed. Refreshing talks to the
+// auth servers, so it runs without holding the lock.
+func (s *SessionService) renew(r *renewal) {
+	session, err := LoadSession()
+	if err == nil && session == nil {
+		err = fmt.Errorf("no session found - please login")
+	}
+	var renewed *AuthSession
+	if err == nil {
+		renewed, err = renewSession(session)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer close(r.done)
+	if s.renewal == r {
+		s.renewal = nil
+	}
+
+	if s.account != r.account {
+		r.err = errAccountSwitched
+		return
+	}
+	if err != nil {
+		r.err = err
+		if session != nil {
+			event := sessionEvent(session)
+			event.Error = err.Error()
+			s.emit(EventAuthFailed, event)
+		}
+		return
+	}
+
+	r.session = renewed
+	s.session = renewed
+	s.failures = 0
+	s.schedule(nextRefresh(renewed, time.Now(), rand.N(refreshJitter)))
+}
+
+// reload reads the active session without touching the network and schedules its refresh
+func (s *SessionService) reload() {
+	s.cancel()
+	if !s.running {
+		return
+	}
+
+	session, err := LoadSession()
+	if err != nil || session == nil {
+		return
+	}
+	s.session = session
+	s.schedule(nextRefresh(session, time.Now(), rand.N(refreshJitter)))
+}
+
+// schedule runs a refresh after delay, replacing any scheduled one
+func (s *SessionService) schedule(delay time.Duration) {
+	s.cancel()
+	if !s.running {
+		return
+	}
+	s.timer = time.AfterFunc(delay, s.refresh)
+}
+
+// cancel stops the scheduled refresh
+func (s *SessionService) cancel() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// refresh renews the tokens of the cached session and schedules the next refresh.
+// Failures are retried with backoff; the account is kept so the user can retry later.
+func (s *SessionService) refresh() {
+	s.mu.Lock()
+	session := s.session
+	if !s.running || session == nil {
+		s.mu.Unlock()
+		return
+	}
+	r, started := s.startRenewal()
+	s.mu.Unlock()
+	if !started {
+		return // Session is renewing the tokens and schedules the next refresh
+	}
+
+	s.emit(EventAuthExpiring, sessionEvent(session))
+
+	// Network and disk work runs without the lock so that Invalidate does not wait for it
+	refreshed, err := refreshTokens(session)
+	active := true
+	if err == nil {
+		active, err = updateActiveSession(refreshed)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer close(r.done)
+	if s.renewal == r {
+		s.renewal = nil
+	}
+
+	if s.account != r.account || (err == nil && !active) {
+		// The user switched accounts meanwhile; the new account has its own refresh
+		fmt.Printf("Dropped background refresh of %s, it is no longer the active account\n", session.Username)
+		r.err = errAccountSwitched
+		return
+	}
+	if err != nil {
+		r.err = err
+		if !s.running {
+			return
+		}
+		s.failures++
+		event := sessionEvent(session)
+		event.Error = err.Error()
+		event.Retrying = s.failures <= refreshMaxRetries
+
+		fmt.Printf("Background session refresh failed (attempt %d): %v\n", s.failures, err)
+		s.emit(EventAuthFailed, event)
+		if event.Retrying {
+			s.schedule(retryDelay(s.failures))
+		}
+		return
+	}
+
+	r.session = refreshed
+	s.session = refreshed
+	s.failures = 0
+	fmt.Printf("Refreshed session of %s in the background\n", refreshed.Username)
+	s.emit(EventAuthRefreshed, sessionEvent(refreshed))
+	s.schedule(nextRefresh(refreshed, time.Now(), rand.N(refreshJitter)))
+}
+
+// emit forwards an event to the listener, if any
+func (s *SessionService) emit(event string, data interface{}) {
+	if s.listener != nil {
+		s.listener(event, data)
+	}
+}
+
+// refreshTokens renews the OAuth tokens if they expire soon and creates a new game session
+func refreshTokens(session *AuthSession) (*AuthSession, error) {
+	if time.Until(session.ExpiresAt) <= refreshLead {
+		return RefreshSession(session)
+	}
+
+	renewed := *session
+	if err := createGameSession(&renewed); err != nil {
+		return nil, fmt.Errorf("failed to create game session: %w", err)
+	}
+	return &renewed, nil
+}
+
+// nextRefresh returns how long to wait before refreshing a session: refreshLead plus jitter
+// before the OAuth or game tokens expire, whichever is first
+func nextRefresh(session *AuthSession, now time.Time, jitter time.Duration) time.Duration {
+	expiresAt := session.ExpiresAt
+	if session.SessionToken != "" && session.GameSessionExpiresAt.Before(expiresAt) {
+		expiresAt = session.GameSessionExpiresAt
+	}
+
+	delay := expiresAt.Sub(now) - refreshLead - jitter
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// retryDelay returns the delay before retrying a refresh after failures consecutive failures
+func retryDelay(failures int) time.Duration {
+	delay := refreshRetryBase
+	for i := 1; i < failures && delay < refreshRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > refreshRetryMax {
+		delay = refreshRetryMax
+	}
+	return delay
+}
+
+// sessionEvent describes a session in session health events
+func sessionEvent(session *AuthSession) SessionEvent {
+	return SessionEvent{
+		Key:       session.Key(),
+		Username:  session.Username,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
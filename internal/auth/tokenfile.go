@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/util"
+)
+
+// Key sources of the encrypted token file
+const (
+	keyMachine    = "machine"    // derived from the machine ID and user account
+	keyPassphrase = "passphrase" // derived from a passphrase the user entered
+	keyPortable   = "portable"   // random key stored next to the token file, for portable installs
+)
+
+// ErrTokensLocked is returned when the token file is protected by a passphrase that was not entered
+var ErrTokensLocked = errors.New("saved logins are protected by a passphrase - unlock them first")
+
+// TokenPassphraseEnv can hold the passphrase of the token file, e.g. for CLI use
+const TokenPassphraseEnv = "HYPRISM_TOKEN_PASSPHRASE"
+
+// encryptedTokens is the on-disk format of the token file
+type encryptedTokens struct {
+	Version int    `json:"version"`
+	Key     string `json:"key"` // machine or passphrase
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"` // AES-GCM sealed JSON object of account key to tokens
+}
+
+// TokenStorageInfo describes where tokens are stored
+type TokenStorageInfo struct {
+	Store               string `json:"store"`
+	PassphraseProtected bool   `json:"passphraseProtected"`
+	Locked              bool   `json:"locked"`
+}
+
+// encryptedFile stores tokens in an AES-GCM encrypted file. It is used when the OS
+// secret store is not available.
+type encryptedFile struct {
+	mu         sync.Mutex
+	passphrase string
+
+	// last derived key, scrypt is deliberately slow
+	keySalt   []byte
+	keySecret string
+	key       []byte
+}
+
+var tokenFile = &encryptedFile{passphrase: os.Getenv(TokenPassphraseEnv)}
+
+// GetTokenFilePath returns the path of the encrypted token file
+func GetTokenFilePath() string {
+	return filepath.Join(env.GetDefaultAppDir(), "tokens.enc")
+}
+
+func (f *encryptedFile) Name() string {
+	return "encrypted file"
+}
+
+func (f *encryptedFile) Get(key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, _, err := f.read()
+	if err != nil {
+		return nil, false, err
+	}
+	secret, ok := secrets[key]
+	return secret, ok, nil
+}
+
+func (f *encryptedFile) Set(key string, secret []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, _, err := f.read()
+	if err != nil {
+		return err
+	}
+	secrets[key] = secret
+	return f.write(secrets)
+}
+
+func (f *encryptedFile) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, exists, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok || !exists {
+		return nil
+	}
+	delete(secrets, key)
+	return f.write(secrets)
+}
+
+// read decrypts the token file. A missing file reads as empty. A file bound to another
+// machine cannot be decrypted; it is moved aside and reads as empty, so restored backups
+// do not carry logins and the next write does not destroy it.
+func (f *encryptedFile) read() (map[string][]byte, bool, error) {
+	secrets := map[string][]byte{}
+
+	data, err := os.ReadFile(GetTokenFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var file encryptedTokens
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, true, fmt.Errorf("failed to parse token file: %w", err)
+	}
+
+	secret, err := f.secret(file.Key)
+	if err != nil {
+		return nil, true, err
+	}
+
+	gcm, err := f.cipher(file.Salt, secret)
+	if err != nil {
+		return nil, true, err
+	}
+
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		if file.Key == keyPassphrase {
+			return nil, true, fmt.Errorf("wrong passphrase for saved logins")
+		}
+		// Keep the file in case it can be read again, e.g. after the machine ID is restored
+		path := GetTokenFilePath()
+		if err := os.Rename(path, path+".undecryptable"); err != nil {
+			return nil, true, fmt.Errorf("saved logins cannot be decrypted and could not be moved aside: %w", err)
+		}
+		fmt.Printf("Warning: saved logins were encrypted on another machine and cannot be read, moved them to %s.undecryptable - please login again\n", path)
+		return secrets, false, nil
+	}
+
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, true, fmt.Errorf("failed to parse token file: %w", err)
+	}
+	return secrets, true, nil
+}
+
+// write encrypts secrets into the token file with a fresh salt and nonce
+func (f *encryptedFile) write(secrets map[string][]byte) error {
+	keySource := keyMachine
+	if env.IsPortable() {
+		keySource = keyPortable
+	}
+	if f.passphrase != "" {
+		keySource = keyPassphrase
+	}
+
+	secret, err := f.secret(keySource)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := f.cipher(salt, secret)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptedTokens{
+		Version: 1,
+		Key:     keySource,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := GetTokenFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+// secret returns the secret the file key is derived from
+func (f *encryptedFile) secret(keySource string) (string, error) {
+	switch keySource {
+	case keyPassphrase:
+		if f.passphrase == "" {
+			return "", ErrTokensLocked
+		}
+		return f.passphrase, nil
+	case keyMachine:
+		return machineSecret(), nil
+	case keyPortable:
+		return portableSecret()
+	}
+	return "", fmt.Errorf("unknown token file key: %s", keySource)
+}
+
+// cipher derives the file key from a secret and salt
+func (f *encryptedFile) cipher(salt []byte, secret string) (cipher.AEAD, error) {
+	if f.key == nil || f.keySecret != secret || string(f.keySalt) != string(salt) {
+		key, err := scrypt.Key([]byte(secret), salt, 1<<15, 8, 1, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive token key: %w", err)
+		}
+		f.key, f.keySalt, f.keySecret = key, salt, secret
+	}
+
+	block, err := aes.NewCipher(f.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// machineSecret binds the token file to this machine and user account
+func machineSecret() string {
+	h := sha256.New()
+	h.Write([]byte("HyPrism token file\x00"))
+	h.Write([]byte(machineID()))
+	h.Write([]byte{0})
+	if u, err := user.Current(); err == nil {
+		h.Write([]byte(u.Uid + "\x00" + u.Username + "\x00"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		h.Write([]byte(home))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// portableSecret returns the random key of a portable install, creating it if needed. It travels
+// with the install, so anyone holding the drive can use the logins unless a passphrase is set.
+func portableSecret() (string, error) {
+	path := filepath.Join(env.GetDefaultAppDir(), "tokens.key")
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		return string(data), nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read token key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	secret := fmt.Sprintf("%x", key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, []byte(secret), 0600); err != nil {
+		return "", fmt.Errorf("failed to write token key: %w", err)
+	}
+	return secret, nil
+}
+
+// SetTokenPassphrase re-encrypts the token file with a passphrase. An empty passphrase
+// binds it to this machine again. The passphrase is never stored.
+func SetTokenPassphrase(passphrase string) error {
+	tokenFile.mu.Lock()
+	defer tokenFile.mu.Unlock()
+
+	secrets, _, err := tokenFile.read()
+	if err != nil {
+		return err
+	}
+	tokenFile.passphrase = passphrase
+	return tokenFile.write(secrets)
+}
+
+// UnlockTokens enters the passphrase of the token file for this run of the launcher
+func UnlockTokens(passphrase string) error {
+	tokenFile.mu.Lock()
+	defer tokenFile.mu.Unlock()
+
+	previous := tokenFile.passphrase
+	tokenFile.passphrase = passphrase
+	if _, _, err := tokenFile.read(); err != nil {
+		tokenFile.passphrase = previous
+		return err
+	}
+	return nil
+}
+
+// GetTokenStorage reports where tokens are stored and whether the token file is locked
+func GetTokenStorage() TokenStorageInfo {
+	info := TokenStorageInfo{Store: TokenStoreName()}
+
+	tokenFile.mu.Lock()
+	defer tokenFile.mu.Unlock()
+
+	data, err := os.ReadFile(GetTokenFilePath())
+	if err != nil {
+		return info
+	}
+	var file encryptedTokens
+	if json.Unmarshal(data, &file) == nil && file.Key == keyPassphrase {
+		info.PassphraseProtected = true
+		info.Locked = tokenFile.passphrase == ""
+	}
+	return info
+}
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"HyPrism/internal/env"
+	"HyPrism/internal/util"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -13,24 +17,40 @@ func configPath() string {
 	return filepath.Join(env.GetDefaultAppDir(), "config.toml")
 }
 
+// GetConfigPath returns the path of the config file
+func GetConfigPath() string {
+	return configPath()
+}
+
 // Save saves the configuration to disk
 func Save(cfg *Config) error {
+	if cfg.SchemaVersion < CurrentSchemaVersion {
+		cfg.SchemaVersion = CurrentSchemaVersion
+	}
 	data, err := toml.Marshal(cfg)
 	if err != nil {
 		return err
 	}
 
+	// Settings of a newer HyPrism are kept, so it still finds them after this version saved
+	if cfg.SchemaVersion > CurrentSchemaVersion {
+		if data, err = keepUnknownKeys(data); err != nil {
+			return err
+		}
+	}
+
 	configDir := filepath.Dir(configPath())
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath(), data, 0644)
+	return util.WriteFileWithBackup(configPath(), data, 0644)
 }
 
-// Load loads the configuration from disk
+// Load loads the configuration from disk. Files written by older versions are upgraded
+// through the migration chain; the original file is backed up first.
 func Load() (*Config, error) {
-	data, err := os.ReadFile(configPath())
+	data, err := util.ReadFileWithBackup(configPath(), validTOML)
 	if err != nil {
 		if os.IsNotExist(err) {
 			cfg := Default()
@@ -42,10 +62,104 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := toml.Unmarshal(data, &cfg); err != nil {
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
 		return nil, err
 	}
 
+	from, err := Migrate(doc)
+	if err != nil {
+		return nil, err
+	}
+	if from > CurrentSchemaVersion {
+		fmt.Printf("Warning: config was written by a newer HyPrism (schema %d), unknown settings are kept but not used\n", from)
+	}
+
+	migrated := from < CurrentSchemaVersion
+	if migrated {
+		backup := backupPath(from)
+		if err := util.WriteFileAtomic(backup, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to back up config before migration: %w", err)
+		}
+		if data, err = toml.Marshal(doc); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Migrated config from schema %d to %d (backup: %s)\n", from, CurrentSchemaVersion, backup)
+	}
+
+	var cfg Config
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		var unknown *toml.StrictMissingError
+		if !errors.As(err, &unknown) {
+			return nil, err
+		}
+		fmt.Printf("Warning: ignoring unknown config settings:\n%s\n", unknown.String())
+	}
+
+	if migrated {
+		if err := Save(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	return &cfg, nil
 }
+
+// keepUnknownKeys adds the keys of the config file on disk that data does not have
+func keepUnknownKeys(data []byte) ([]byte, error) {
+	existing, err := os.ReadFile(configPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+
+	var current, saved map[string]interface{}
+	if err := toml.Unmarshal(existing, &current); err != nil {
+		return nil, fmt.Errorf("failed to read settings of the newer config: %w", err)
+	}
+	if err := toml.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	for key, value := range current {
+		if _, ok := saved[key]; !ok {
+			saved[key] = value
+		}
+	}
+	return toml.Marshal(saved)
+}
+
+// FileKeys returns the keys that are set in the config file on disk
+func FileKeys() (map[string]bool, error) {
+	data, err := os.ReadFile(configPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(doc))
+	for key := range doc {
+		keys[key] = true
+	}
+	return keys, nil
+}
+
+// validTOML reports whether data is a readable config file
+func validTOML(data []byte) error {
+	var doc map[string]interface{}
+	return toml.Unmarshal(data, &doc)
+}
+
+// backupPath returns where a config file of an older schema is kept before it is migrated
+func backupPath(schemaVersion int) string {
+	return fmt.Sprintf("%s.v%d.bak", configPath(), schemaVersion)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTempConfigDir points the config file of every platform at a temporary directory
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+}
+
+func TestSaveKeepsSettingsOfNewerSchema(t *testing.T) {
+	useTempConfigDir(t)
+
+	newer := `schema_version = 99
+patchline = "pre-release"
+future_setting = "keep me"
+`
+	if err := os.MkdirAll(filepath.Dir(configPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath(), []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Patchline != "pre-release" {
+		t.Errorf("Patchline = %q, want the value from the file", cfg.Patchline)
+	}
+
+	cfg.MusicEnabled = true
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	keys, err := FileKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keys["future_setting"] {
+		t.Error("Save() dropped a setting of the newer schema")
+	}
+
+	saved, err := Load()
+	if err != nil {
+		t.Fatalf("Load() after Save() error = %v", err)
+	}
+	if saved.SchemaVersion != 99 || !saved.MusicEnabled {
+		t.Errorf("saved config = %+v, want schema 99 with music enabled", saved)
+	}
+}
@@ -1,15 +1,29 @@
 package config
 
 type Config struct {
-	Version         string `toml:"version" json:"version"`
-	MusicEnabled    bool   `toml:"music_enabled" json:"musicEnabled"`
-	GameInstallPath string `toml:"game_install_path" json:"gameInstallPath"`
+	SchemaVersion   int               `toml:"schema_version" json:"schemaVersion"` // layout of the config file, see migrate.go
+	Version         string            `toml:"version" json:"version"`
+	MusicEnabled    bool              `toml:"music_enabled" json:"musicEnabled"`
+	GameInstallPath string            `toml:"game_install_path" json:"gameInstallPath"`
+	LaunchArgs      string            `toml:"launch_args" json:"launchArgs"`         // extra client arguments
+	LaunchEnv       map[string]string `toml:"launch_env" json:"launchEnv"`           // extra environment variables
+	LaunchWrapper   string            `toml:"launch_wrapper" json:"launchWrapper"`   // command prefix, e.g. "gamemoderun"
+	DisplayBackend  string            `toml:"display_backend" json:"displayBackend"` // auto, wayland or x11 (Linux only)
+	Patchline       string            `toml:"patchline" json:"patchline"`            // e.g. "release" or "pre-release"
+	GameBuild       string            `toml:"game_build" json:"gameBuild"`           // build directory, e.g. "latest"
 }
 
 func Default() *Config {
 	return &Config{
+		SchemaVersion:   CurrentSchemaVersion,
 		Version:         "1.0.0",
 		MusicEnabled:    true,
 		GameInstallPath: "",
+		LaunchArgs:      "",
+		LaunchEnv:       map[string]string{},
+		LaunchWrapper:   "",
+		DisplayBackend:  "auto",
+		Patchline:       "release",
+		GameBuild:       "latest",
 	}
 }
@@ -0,0 +1,73 @@
+package config
+
+import "fmt"
+
+// CurrentSchemaVersion is the config file layout this build reads and writes.
+// Bump it together with a new entry in migrations whenever keys are added, renamed or removed.
+const CurrentSchemaVersion = 1
+
+// Migration upgrades a decoded config file by one schema version. It only works on the
+// document passed in, so every step can be tested on its own.
+type Migration func(doc map[string]interface{}) error
+
+// migrations[i] upgrades schema version i to i+1. Migrations must not use Default():
+// they describe the file as it was at that version, not as it is today.
+var migrations = []Migration{
+	migrateV0ToV1,
+}
+
+// SchemaVersion returns the schema version of a decoded config file. Files written
+// before versioning have none and are version 0.
+func SchemaVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok {
+		return 0, nil
+	}
+	version := -1
+	switch v := raw.(type) {
+	case int64:
+		version = int(v)
+	case int:
+		version = v
+	}
+	if version < 0 {
+		return 0, fmt.Errorf("invalid schema_version: %v", raw)
+	}
+	return version, nil
+}
+
+// Migrate upgrades a decoded config file to CurrentSchemaVersion in place and returns the
+// version it started from. Files from a newer HyPrism are left alone.
+func Migrate(doc map[string]interface{}) (int, error) {
+	from, err := SchemaVersion(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	for v := from; v < CurrentSchemaVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return from, fmt.Errorf("failed to migrate config from schema %d to %d: %w", v, v+1, err)
+		}
+		doc["schema_version"] = int64(v + 1)
+	}
+	return from, nil
+}
+
+// migrateV0ToV1 fills in the settings added after 1.0.0. Without them an old file
+// decodes to empty values instead of the defaults, e.g. no patchline.
+func migrateV0ToV1(doc map[string]interface{}) error {
+	added := map[string]interface{}{
+		"launch_args":     "",
+		"launch_env":      map[string]interface{}{},
+		"launch_wrapper":  "",
+		"display_backend": "auto",
+		"patchline":       "release",
+		"game_build":      "latest",
+	}
+	for key, value := range added {
+		if _, ok := doc[key]; !ok {
+			doc[key] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// v0Config is a config file as written by HyPrism 1.0.0, before schema versions
+const v0Config = `
+version = "1.0.0"
+music_enabled = true
+game_install_path = "/games/hytale"
+launch_args = "--fullscreen"
+`
+
+func decodeDoc(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := toml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	return doc
+}
+
+func TestSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]interface{}
+		want    int
+		wantErr bool
+	}{
+		{"missing", map[string]interface{}{}, 0, false},
+		{"current", map[string]interface{}{"schema_version": int64(1)}, 1, false},
+		{"newer", map[string]interface{}{"schema_version": int64(7)}, 7, false},
+		{"negative", map[string]interface{}{"schema_version": int64(-1)}, 0, true},
+		{"string", map[string]interface{}{"schema_version": "1"}, 0, true},
+		{"float", map[string]interface{}{"schema_version": 1.5}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SchemaVersion(tt.doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SchemaVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SchemaVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateV0ToV1(t *testing.T) {
+	doc := decodeDoc(t, v0Config)
+	if err := migrateV0ToV1(doc); err != nil {
+		t.Fatalf("migrateV0ToV1() error = %v", err)
+	}
+
+	// Settings that already exist are kept
+	if doc["launch_args"] != "--fullscreen" {
+		t.Errorf("launch_args = %v, want the value from the file", doc["launch_args"])
+	}
+	if doc["game_install_path"] != "/games/hytale" {
+		t.Errorf("game_install_path = %v, want the value from the file", doc["game_install_path"])
+	}
+
+	// Settings added after 1.0.0 get their defaults
+	for key, want := range map[string]interface{}{
+		"launch_wrapper":  "",
+		"display_backend": "auto",
+		"patchline":       "release",
+		"game_build":      "latest",
+	} {
+		if doc[key] != want {
+			t.Errorf("%s = %v, want %v", key, doc[key], want)
+		}
+	}
+	if _, ok := doc["launch_env"].(map[string]interface{}); !ok {
+		t.Errorf("launch_env = %v, want an empty table", doc["launch_env"])
+	}
+}
+
+func TestMigrateChain(t *testing.T) {
+	doc := decodeDoc(t, v0Config)
+
+	from, err := Migrate(doc)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if from != 0 {
+		t.Errorf("Migrate() from = %d, want 0", from)
+	}
+
+	// The migrated document must decode into the current Config without unknown keys
+	data, err := toml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode migrated config: %v", err)
+	}
+	var cfg Config
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		t.Fatalf("failed to decode migrated config: %v", err)
+	}
+
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, CurrentSchemaVersion)
+	}
+	if cfg.Version != "1.0.0" || !cfg.MusicEnabled || cfg.GameInstallPath != "/games/hytale" || cfg.LaunchArgs != "--fullscreen" {
+		t.Errorf("settings from the file were not kept: %+v", cfg)
+	}
+	if cfg.Patchline != "release" || cfg.GameBuild != "latest" || cfg.DisplayBackend != "auto" {
+		t.Errorf("added settings did not get their defaults: %+v", cfg)
+	}
+
+	// Migrating again is a no-op
+	again, err := Migrate(doc)
+	if err != nil || again != CurrentSchemaVersion {
+		t.Errorf("second Migrate() = %d, %v, want %d, nil", again, err, CurrentSchemaVersion)
+	}
+}
+
+func TestMigrateLeavesNewerFilesAlone(t *testing.T) {
+	doc := map[string]interface{}{"schema_version": int64(CurrentSchemaVersion + 1), "future_key": "x"}
+
+	from, err := Migrate(doc)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if from != CurrentSchemaVersion+1 {
+		t.Errorf("Migrate() from = %d, want %d", from, CurrentSchemaVersion+1)
+	}
+	if doc["schema_version"] != int64(CurrentSchemaVersion+1) || doc["future_key"] != "x" {
+		t.Errorf("newer file was changed: %v", doc)
+	}
+}
+
+func TestMigrateRejectsNegativeVersion(t *testing.T) {
+	doc := map[string]interface{}{"schema_version": int64(-1)}
+	if _, err := Migrate(doc); err == nil {
+		t.Fatal("Migrate() accepted a negative schema_version")
+	}
+}
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	if len(migrations) != CurrentSchemaVersion {
+		t.Errorf("len(migrations) = %d, want one per schema version (%d)", len(migrations), CurrentSchemaVersion)
+	}
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sources of an effective config value
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+const (
+	// EnvPrefix prefixes the environment variable of a config key, e.g. HYPRISM_GAME_INSTALL_PATH
+	EnvPrefix = "HYPRISM_"
+	// SetFlag sets a config key for this run, e.g. --set patchline=pre-release
+	SetFlag = "--set"
+	// NoSaveOverridesFlag keeps overridden values out of the config file
+	NoSaveOverridesFlag = "--no-save-overrides"
+	// NoSaveOverridesEnv does the same as NoSaveOverridesFlag when set to a true value
+	NoSaveOverridesEnv = "HYPRISM_NO_SAVE_OVERRIDES"
+)
+
+// Override is a config value set outside the config file
+type Override struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // env or flag
+}
+
+// Setting is the effective value of a config key and where it came from
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // default, file, env or flag
+	EnvVar string `json:"envVar"`
+}
+
+// Overrides holds the config values set through environment variables and flags.
+// Flags win over environment variables.
+type Overrides struct {
+	values map[string]Override
+	// NoSave keeps overridden values out of the config file when it is saved
+	NoSave bool
+}
+
+// ParseOverrides collects overrides from HYPRISM_* environment variables and --set key=value flags
+func ParseOverrides(environ []string, args []string) (*Overrides, error) {
+	o := &Overrides{values: map[string]Override{}}
+	fields := configFields()
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		if name == NoSaveOverridesEnv {
+			o.NoSave, _ = strconv.ParseBool(value)
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+		if _, ok := fields[key]; ok {
+			o.values[key] = Override{Key: key, Value: value, Source: SourceEnv}
+		}
+	}
+
+	for i := 0; i < len(args); i++ {
+		var assignment string
+		switch {
+		case args[i] == NoSaveOverridesFlag:
+			o.NoSave = true
+			continue
+		case args[i] == SetFlag:
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s needs a key=value argument", SetFlag)
+			}
+			i++
+			assignment = args[i]
+		case strings.HasPrefix(args[i], SetFlag+"="):
+			assignment = strings.TrimPrefix(args[i], SetFlag+"=")
+		default:
+			continue
+		}
+
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s argument %q, expected key=value", SetFlag, assignment)
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := fields[key]; !ok {
+			return nil, fmt.Errorf("unknown config key %q (known keys: %s)", key, strings.Join(Keys(), ", "))
+		}
+		o.values[key] = Override{Key: key, Value: value, Source: SourceFlag}
+	}
+
+	return o, nil
+}
+
+// Keys returns every config key that can be overridden, in file order
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if key := tomlKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// List returns the overrides, ordered by key
+func (o *Overrides) List() []Override {
+	list := make([]Override, 0, len(o.values))
+	for _, override := range o.values {
+		list = append(list, override)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// Apply sets the overridden values in cfg
+func (o *Overrides) Apply(cfg *Config) error {
+	fields := configFields()
+	v := reflect.ValueOf(cfg).Elem()
+	for _, override := range o.List() {
+		field := v.Field(fields[override.Key])
+		if err := setField(field, override.Value); err != nil {
+			return fmt.Errorf("invalid value for %s (from %s): %w", override.Key, override.Source, err)
+		}
+	}
+	return nil
+}
+
+// WithoutOverrides returns a copy of cfg to save: every overridden key that still has its
+// overridden value gets its value from file, the config as read from disk, again
+func (o *Overrides) WithoutOverrides(cfg, file *Config) (*Config, error) {
+	overridden := file.Clone()
+	if err := o.Apply(overridden); err != nil {
+		return nil, err
+	}
+
+	fields := configFields()
+	out := cfg.Clone()
+	outValue := reflect.ValueOf(out).Elem()
+	overriddenValue := reflect.ValueOf(overridden).Elem()
+	fileValue := reflect.ValueOf(file.Clone()).Elem()
+	for key := range o.values {
+		i := fields[key]
+		if formatField(outValue.Field(i)) == formatField(overriddenValue.Field(i)) {
+			outValue.Field(i).Set(fileValue.Field(i))
+		}
+	}
+	return out, nil
+}
+
+// Settings reports the effective value of every key of cfg and where it came from.
+// Values that are not overridden count as file values if their key is in fileKeys.
+func (o *Overrides) Settings(cfg *Config, fileKeys map[string]bool) []Setting {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	var settings []Setting
+	for i := 0; i < t.NumField(); i++ {
+		key := tomlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+
+		value := formatField(v.Field(i))
+		source := SourceDefault
+		if override, ok := o.values[key]; ok {
+			source = override.Source
+		} else if fileKeys[key] {
+			source = SourceFile
+		}
+
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  value,
+			Source: source,
+			EnvVar: EnvPrefix + strings.ToUpper(key),
+		})
+	}
+	return settings
+}
+
+// Clone returns a deep copy of the config
+func (c *Config) Clone() *Config {
+	clone := *c
+	if c.LaunchEnv != nil {
+		clone.LaunchEnv = make(map[string]string, len(c.LaunchEnv))
+		for k, v := range c.LaunchEnv {
+			clone.LaunchEnv[k] = v
+		}
+	}
+	return &clone
+}
+
+// configFields maps overridable config keys to their field index
+func configFields() map[string]int {
+	t := reflect.TypeOf(Config{})
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		if key := tomlKey(t.Field(i)); key != "" {
+			fields[key] = i
+		}
+	}
+	return fields
+}
+
+// tomlKey returns the key of a config field, or "" if it cannot be overridden
+func tomlKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	if key == "-" || key == "schema_version" {
+		return ""
+	}
+	return key
+}
+
+// setField parses value into a config field. Maps are written as KEY=VALUE pairs separated by commas.
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		field.SetInt(n)
+	case reflect.Map:
+		m := map[string]string{}
+		for _, pair := range strings.Split(value, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected KEY=VALUE pairs separated by commas")
+			}
+			m[strings.TrimSpace(k)] = v
+		}
+		field.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Kind())
+	}
+	return nil
+}
+
+// formatField formats a config field the way overrides are written
+func formatField(field reflect.Value) string {
+	if field.Kind() != reflect.Map {
+		return fmt.Sprint(field.Interface())
+	}
+
+	pairs := make([]string, 0, field.Len())
+	for _, k := range field.MapKeys() {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", k.Interface(), field.MapIndex(k).Interface()))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
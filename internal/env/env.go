@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // IsFlatpak returns true if running inside a Flatpak sandbox
@@ -20,8 +23,13 @@ func IsFlatpak() bool {
 }
 
 // GetDefaultAppDir returns the default application directory
-// Uses APPDATA (roaming) to store only config and session files
+// Uses APPDATA (roaming) to store only config and session files, or the folder next to
+// the executable in portable mode
 func GetDefaultAppDir() string {
+	if portableDir != "" {
+		return portableDir
+	}
+
 	var baseDir string
 
 	switch runtime.GOOS {
@@ -34,24 +42,115 @@ func GetDefaultAppDir() string {
 		home, _ := os.UserHomeDir()
 		baseDir = filepath.Join(home, "Library", "Application Support")
 	default:
-		home, _ := os.UserHomeDir()
-		baseDir = filepath.Join(home, ".config")
+		return xdgDir("XDG_CONFIG_HOME", ".config")
 	}
 
 	return filepath.Join(baseDir, "HyPrism")
 }
 
+// GetDataDir returns the directory for the JRE, mods and servers.
+// On Linux this is XDG_DATA_HOME, elsewhere the app directory.
+func GetDataDir() string {
+	if !usesXDG() {
+		return GetDefaultAppDir()
+	}
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// GetStateDir returns the directory for logs and other state that is not worth backing up.
+// On Linux this is XDG_STATE_HOME, elsewhere the app directory.
+func GetStateDir() string {
+	if !usesXDG() {
+		return GetDefaultAppDir()
+	}
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
 // CreateFolders creates the required folder structure
-// Only creates the base HyPrism directory for config and session storage
+// Only creates the base HyPrism directories; everything else is created when first written
 func CreateFolders() error {
-	appDir := GetDefaultAppDir()
-	return os.MkdirAll(appDir, 0755)
+	for _, dir := range []string{GetDefaultAppDir(), GetDataDir(), GetStateDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Legacy stubs - no longer used but kept for compatibility
-func GetCacheDir() string { return filepath.Join(GetDefaultAppDir(), "cache") }
-func GetJREDir() string { return filepath.Join(GetDefaultAppDir(), "jre") }
-func GetInstanceDir(branch string, version int) string { return "" }
-func GetInstanceGameDir(branch string, version int) string { return "" }
-func GetInstanceUserDataDir(branch string, version int) string { return "" }
+// GetLogsDir returns the directory for launcher and game logs
+func GetLogsDir() string {
+	return filepath.Join(GetStateDir(), "logs")
+}
+
+// GetCacheDir returns the directory for downloads. On Linux this is XDG_CACHE_HOME.
+func GetCacheDir() string {
+	if !usesXDG() {
+		return filepath.Join(GetDefaultAppDir(), "cache")
+	}
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// GetJREDir returns the directory of the bundled Java runtime
+func GetJREDir() string { return filepath.Join(GetDataDir(), "jre") }
+
+// gameInstallPath is the official Hytale installation that instance paths resolve against
+var (
+	gameInstallPath   string
+	gameInstallPathMu sync.RWMutex
+)
 
+// SetGameInstallPath sets the official Hytale installation used to resolve instance directories
+func SetGameInstallPath(path string) {
+	gameInstallPathMu.Lock()
+	defer gameInstallPathMu.Unlock()
+	gameInstallPath = path
+}
+
+// GetGameInstallPath returns the official Hytale installation, or "" if none is configured
+func GetGameInstallPath() string {
+	gameInstallPathMu.RLock()
+	defer gameInstallPathMu.RUnlock()
+	return gameInstallPath
+}
+
+// GetBuildName maps an instance version to its build directory name; 0 is the "latest" build
+func GetBuildName(version int) string {
+	if version <= 0 {
+		return "latest"
+	}
+	return strconv.Itoa(version)
+}
+
+// isValidBranch reports whether a patchline name is a single directory name
+// Names with path separators or ".." would resolve outside the install directory
+func isValidBranch(branch string) bool {
+	return branch != "" && branch != "." && !strings.Contains(branch, "..") && !strings.ContainsAny(branch, `/\`)
+}
+
+// GetInstanceDir returns the package directory of a patchline in the official installation,
+// or "" if no installation is configured or the patchline name is invalid
+func GetInstanceDir(branch string, version int) string {
+	installPath := GetGameInstallPath()
+	if installPath == "" || !isValidBranch(branch) {
+		return ""
+	}
+	return filepath.Join(installPath, "install", branch, "package")
+}
+
+// GetInstanceGameDir returns the game directory of a patchline build
+func GetInstanceGameDir(branch string, version int) string {
+	instanceDir := GetInstanceDir(branch, version)
+	if instanceDir == "" {
+		return ""
+	}
+	return filepath.Join(instanceDir, "game", GetBuildName(version))
+}
+
+// GetInstanceUserDataDir returns the UserData directory, which all patchlines and builds share
+func GetInstanceUserDataDir(branch string, version int) string {
+	installPath := GetGameInstallPath()
+	if installPath == "" {
+		return ""
+	}
+	return filepath.Join(installPath, "UserData")
+}
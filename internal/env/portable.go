@@ -0,0 +1,67 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// PortableMarker is the file next to the executable that turns on portable mode
+	PortableMarker = "portable.txt"
+	// PortableFlag turns on portable mode for one run
+	PortableFlag = "--portable"
+	// PortableDataDir is the folder next to the executable that holds all state in portable mode
+	PortableDataDir = "HyPrismData"
+)
+
+// portableDir is the app directory in portable mode, "" otherwise
+var portableDir string
+
+// DetectPortable turns on portable mode if the marker file exists next to the executable
+// in exeDir or args contain the portable flag. It must run before any path is resolved.
+func DetectPortable(exeDir string, args []string) bool {
+	baseDir := portableBaseDir(exeDir)
+
+	enabled := false
+	for _, arg := range args {
+		if arg == PortableFlag {
+			enabled = true
+		}
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, PortableMarker)); err == nil {
+		enabled = true
+	}
+	if !enabled {
+		return false
+	}
+
+	dir := filepath.Join(baseDir, PortableDataDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("Warning: portable mode is not available, %s is not writable: %v\n", baseDir, err)
+		return false
+	}
+
+	portableDir = dir
+	fmt.Printf("Portable mode: storing all data in %s\n", dir)
+	return true
+}
+
+// IsPortable returns true if all state is kept next to the executable
+func IsPortable() bool {
+	return portableDir != ""
+}
+
+// portableBaseDir returns the folder the user sees the launcher in. AppImages run from a
+// temporary mount and macOS executables live inside the app bundle.
+func portableBaseDir(exeDir string) string {
+	if appImage := os.Getenv("APPIMAGE"); appImage != "" {
+		return filepath.Dir(appImage)
+	}
+	if bundle := filepath.Dir(filepath.Dir(exeDir)); strings.HasSuffix(bundle, ".app") &&
+		filepath.Base(exeDir) == "MacOS" && filepath.Base(filepath.Dir(exeDir)) == "Contents" {
+		return filepath.Dir(bundle)
+	}
+	return exeDir
+}
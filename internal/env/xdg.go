@@ -0,0 +1,152 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"HyPrism/internal/util"
+)
+
+// layoutMarker is written to the config directory once older data has been moved
+// to the XDG directories
+const layoutMarker = ".xdg-layout"
+
+// usesXDG returns true if state is split across the XDG Base Directories
+func usesXDG() bool {
+	return portableDir == "" && runtime.GOOS != "windows" && runtime.GOOS != "darwin"
+}
+
+// xdgDir returns the HyPrism folder in the XDG base directory named by variable, or in
+// fallback under the home directory if it is unset or relative, as the spec requires
+func xdgDir(variable string, fallback ...string) string {
+	baseDir := os.Getenv(variable)
+	if !filepath.IsAbs(baseDir) {
+		home, _ := os.UserHomeDir()
+		baseDir = filepath.Join(append([]string{home}, fallback...)...)
+	}
+	return filepath.Join(baseDir, "HyPrism")
+}
+
+// legacyMove is a folder of the old layout and where it belongs now
+type legacyMove struct {
+	from       string
+	to         string
+	disposable bool // dropped instead of copied if it cannot be renamed
+}
+
+// MigrateLegacyLayout moves data that older versions kept in ~/.config/HyPrism to the XDG
+// data, cache and state directories. It runs once; after a failure it is retried on the next start.
+func MigrateLegacyLayout() error {
+	if !usesXDG() {
+		return nil
+	}
+
+	configDir := GetDefaultAppDir()
+	marker := filepath.Join(configDir, layoutMarker)
+	if _, err := os.Stat(marker); err == nil {
+		return nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	legacyDir := filepath.Join(home, ".config", "HyPrism")
+
+	if _, err := os.Stat(legacyDir); err == nil {
+		moves := []legacyMove{
+			{from: "jre", to: GetJREDir()},
+			{from: "UserData", to: filepath.Join(GetDataDir(), "UserData")},
+			{from: "servers", to: filepath.Join(GetDataDir(), "servers")},
+			{from: "backups", to: filepath.Join(GetDataDir(), "backups")},
+			{from: "cache", to: GetCacheDir(), disposable: true},
+			{from: "logs", to: GetLogsDir()},
+			{from: "integrity", to: filepath.Join(GetStateDir(), "integrity")},
+		}
+
+		// With XDG_CONFIG_HOME set, the config files move as well
+		if filepath.Clean(configDir) != filepath.Clean(legacyDir) {
+			moves = append(moves, legacyMove{from: ".", to: configDir})
+		}
+
+		var failed []error
+		for _, move := range moves {
+			// Merging the rest of the old folder would carry data that failed to move into the
+			// config directory; it is retried on the next start instead
+			if move.from == "." && len(failed) > 0 {
+				fmt.Printf("Keeping the config files in %s until all data has been moved\n", legacyDir)
+				continue
+			}
+			from := filepath.Join(legacyDir, move.from)
+			if _, err := os.Lstat(from); os.IsNotExist(err) {
+				continue
+			}
+			if err := moveLegacy(from, move.to, move.disposable); err != nil {
+				failed = append(failed, fmt.Errorf("%s: %w", from, err))
+				continue
+			}
+			fmt.Printf("Moved %s to %s\n", from, move.to)
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to move data to the XDG directories: %v", failed)
+		}
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(marker, []byte("1\n"), 0644)
+}
+
+// moveLegacy moves from to to. Folders are merged into an existing destination, and files
+// that already exist there are kept. Moves across file systems fall back to copying.
+func moveLegacy(from, to string, disposable bool) error {
+	info, err := os.Lstat(from)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing, err := os.Lstat(to); err == nil {
+		if !info.IsDir() || !existing.IsDir() {
+			fmt.Printf("Warning: keeping %s in place, %s already exists\n", from, to)
+			return nil
+		}
+
+		entries, err := os.ReadDir(from)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := moveLegacy(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name()), disposable); err != nil {
+				return err
+			}
+		}
+		os.Remove(from) // only succeeds once the folder is empty
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	if disposable {
+		return os.RemoveAll(from)
+	}
+
+	if info.IsDir() {
+		err = util.CopyDir(from, to)
+	} else {
+		err = util.CopyFile(from, to)
+	}
+	if err != nil {
+		os.RemoveAll(to)
+		return err
+	}
+	return os.RemoveAll(from)
+}
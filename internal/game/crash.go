@@ -0,0 +1,356 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/util"
+)
+
+const (
+	// crashLogTailLines is how many lines of the session log go into a crash report
+	crashLogTailLines = 300
+	// crashClientLogFiles is how many of the newest client log files are copied
+	crashClientLogFiles = 3
+	// crashClientLogMaxSize caps each copied client log to its tail
+	crashClientLogMaxSize = 2 * 1024 * 1024
+	// crashPreviewLines is how many log lines are shown in the report preview
+	crashPreviewLines = 5
+	// maxCrashReports is how many crash reports are kept before the oldest are deleted
+	maxCrashReports = 20
+	// maxCrashReportAge is how long crash reports are kept
+	maxCrashReportAge = 30 * 24 * time.Hour
+
+	crashSummaryFile = "report.txt"
+)
+
+// CrashReportInfo describes a stored crash report
+type CrashReportInfo struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	Timestamp string `json:"timestamp"` // RFC 3339
+	Preview   string `json:"preview"`
+}
+
+// GetCrashReportsDir returns the directory where crash reports are stored
+func GetCrashReportsDir() string {
+	return filepath.Join(env.GetLogsDir(), "crash-reports")
+}
+
+// crashReportDir returns the directory of a single crash report
+func crashReportDir(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid crash report id: %s", id)
+	}
+	return filepath.Join(GetCrashReportsDir(), id), nil
+}
+
+// isAbnormalExit reports whether a finished session ended in a way that warrants a crash report
+func isAbnormalExit(info SessionInfo) bool {
+	return info.ExitCode != 0 || info.ExitSignal != "" || info.Error != ""
+}
+
+// CleanExit reports whether a session has finished and exited normally
+func (info SessionInfo) CleanExit() bool {
+	return !info.Running && !isAbnormalExit(info)
+}
+
+// WriteCrashReport collects everything useful about a crashed session into a crash report.
+// args are the client launch arguments; tokens are redacted before they are written.
+func WriteCrashReport(info SessionInfo, args []string, userDataDir string) (string, error) {
+	dir, err := crashReportDir("crash_" + info.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := pruneCrashReports(maxCrashReports-1, maxCrashReportAge); err != nil {
+		fmt.Printf("Warning: failed to prune crash reports: %v\n", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+
+	var tail []string
+	if info.LogFile != "" {
+		tail, err = readTailLines(info.LogFile, crashLogTailLines)
+		if err != nil {
+			fmt.Printf("Warning: failed to read session log for crash report: %v\n", err)
+		} else if err := os.WriteFile(filepath.Join(dir, "session-tail.log"), []byte(strings.Join(tail, "\n")+"\n"), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	var clientLogs []string
+	if userDataDir != "" {
+		clientLogs, err = copyNewestClientLogs(userDataDir, filepath.Join(dir, "client-logs"))
+		if err != nil {
+			fmt.Printf("Warning: failed to copy client logs for crash report: %v\n", err)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("HyPrism Crash Report\n")
+	fmt.Fprintf(&b, "Generated: %s\n\n", time.Now().Format(time.RFC3339))
+
+	b.WriteString("=== SESSION ===\n")
+	fmt.Fprintf(&b, "Session: %s\n", info.ID)
+	fmt.Fprintf(&b, "PID: %d\n", info.PID)
+	fmt.Fprintf(&b, "Started: %s\n", info.StartedAt)
+	fmt.Fprintf(&b, "Ended: %s\n", info.EndedAt)
+	fmt.Fprintf(&b, "Exit Code: %d\n", info.ExitCode)
+	if info.ExitSignal != "" {
+		fmt.Fprintf(&b, "Exit Signal: %s\n", info.ExitSignal)
+	}
+	if info.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", info.Error)
+	}
+
+	b.WriteString("\n=== PLATFORM ===\n")
+	fmt.Fprintf(&b, "OS: %s\n", runtime.GOOS)
+	fmt.Fprintf(&b, "Arch: %s\n", runtime.GOARCH)
+	fmt.Fprintf(&b, "CPUs: %d\n", runtime.NumCPU())
+	if runtime.GOOS == "linux" {
+		fmt.Fprintf(&b, "Session Type: %s\n", os.Getenv("XDG_SESSION_TYPE"))
+		fmt.Fprintf(&b, "Wayland Display: %s\n", os.Getenv("WAYLAND_DISPLAY"))
+		fmt.Fprintf(&b, "Flatpak: %v\n", env.IsFlatpak())
+	}
+
+	b.WriteString("\n=== LAUNCH ARGUMENTS ===\n")
+	for _, arg := range RedactArgs(args) {
+		b.WriteString(arg + "\n")
+	}
+
+	b.WriteString("\n=== CLIENT LOGS ===\n")
+	if len(clientLogs) == 0 {
+		b.WriteString("(none found)\n")
+	}
+	for _, name := range clientLogs {
+		b.WriteString(name + "\n")
+	}
+
+	b.WriteString("\n=== LAST OUTPUT ===\n")
+	start := len(tail) - crashPreviewLines
+	if start < 0 {
+		start = 0
+	}
+	for _, line := range tail[start:] {
+		b.WriteString(line + "\n")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, crashSummaryFile), []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+
+	fmt.Printf("Crash report written to %s\n", dir)
+	return filepath.Base(dir), nil
+}
+
+// ListCrashReports returns the stored crash reports, newest first
+func ListCrashReports() ([]CrashReportInfo, error) {
+	entries, err := os.ReadDir(GetCrashReportsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []CrashReportInfo{}, nil
+		}
+		return nil, err
+	}
+
+	reports := []CrashReportInfo{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(GetCrashReportsDir(), entry.Name())
+		summaryPath := filepath.Join(dir, crashSummaryFile)
+		stat, err := os.Stat(summaryPath)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, CrashReportInfo{
+			ID:        entry.Name(),
+			Path:      dir,
+			Timestamp: stat.ModTime().Format(time.RFC3339),
+			Preview:   crashPreview(summaryPath),
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].ID > reports[j].ID
+	})
+
+	return reports, nil
+}
+
+// pruneCrashReports deletes crash reports older than maxAge and keeps at most keep reports
+func pruneCrashReports(keep int, maxAge time.Duration) error {
+	reports, err := ListCrashReports()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for i, report := range reports {
+		timestamp, _ := time.Parse(time.RFC3339, report.Timestamp)
+		if i < keep && timestamp.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(report.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportCrashReport packs a crash report into a ZIP archive at dest
+func ExportCrashReport(id, dest string) error {
+	dir, err := crashReportDir(id)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("crash report not found: %s", id)
+	}
+	return util.CreateZip(dir, dest)
+}
+
+// crashPreview returns the exit details and last output lines of a crash summary
+func crashPreview(summaryPath string) string {
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		return ""
+	}
+
+	var preview []string
+	inOutput := false
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "Exit Code:"), strings.HasPrefix(line, "Exit Signal:"), strings.HasPrefix(line, "Error:"):
+			preview = append(preview, line)
+		case line == "=== LAST OUTPUT ===":
+			inOutput = true
+		case inOutput && line != "":
+			preview = append(preview, line)
+		}
+	}
+
+	return strings.Join(preview, "\n")
+}
+
+// copyNewestClientLogs copies the newest client log files from UserData into dest
+func copyNewestClientLogs(userDataDir, dest string) ([]string, error) {
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+
+	var files []logFile
+	var dirs []os.FileInfo
+	for _, name := range []string{"Logs", "logs"} {
+		// On case-insensitive file systems both names are the same directory
+		dirInfo, err := os.Stat(filepath.Join(userDataDir, name))
+		if err != nil || !dirInfo.IsDir() {
+			continue
+		}
+		seen := false
+		for _, dir := range dirs {
+			seen = seen || os.SameFile(dir, dirInfo)
+		}
+		if seen {
+			continue
+		}
+		dirs = append(dirs, dirInfo)
+
+		entries, err := os.ReadDir(filepath.Join(userDataDir, name))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			files = append(files, logFile{path: filepath.Join(userDataDir, name, entry.Name()), modTime: info.ModTime()})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	if len(files) > crashClientLogFiles {
+		files = files[:crashClientLogFiles]
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+
+	var copied []string
+	for _, f := range files {
+		data, err := readTail(f.path, crashClientLogMaxSize)
+		if err != nil {
+			return copied, err
+		}
+		name := filepath.Base(f.path)
+		if err := os.WriteFile(filepath.Join(dest, name), data, 0644); err != nil {
+			return copied, err
+		}
+		copied = append(copied, name)
+	}
+
+	return copied, nil
+}
+
+// readTail reads at most maxSize bytes from the end of a file
+func readTail(path string, maxSize int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxSize {
+		if _, err := file.Seek(info.Size()-maxSize, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	return io.ReadAll(file)
+}
+
+// readTailLines returns the last n lines of a file
+func readTailLines(path string, n int) ([]string, error) {
+	data, err := readTail(path, crashClientLogMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
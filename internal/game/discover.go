@@ -0,0 +1,134 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// InstallCandidate is an official Hytale installation found on this machine
+type InstallCandidate struct {
+	Path       string      `json:"path"`
+	Source     string      `json:"source"` // where the candidate was found, e.g. "default", "xdg-data", "flatpak"
+	Patchlines []Patchline `json:"patchlines"`
+}
+
+// installDirNames are the directory names the official launcher installs into
+var installDirNames = []string{"Hytale", "hytale"}
+
+// DiscoverInstalls probes the official launcher's default install locations
+// and returns every valid installation found, in order of preference
+func DiscoverInstalls() []InstallCandidate {
+	candidates := []InstallCandidate{}
+	seen := map[string]bool{}
+
+	for _, probe := range installProbes() {
+		path := filepath.Clean(probe.path)
+		key := path
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			key = resolved
+		}
+		// Windows and macOS file systems are case-insensitive by default
+		if runtime.GOOS != "linux" {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		patchlines, err := ScanPatchlines(path)
+		if err != nil {
+			continue
+		}
+		patchlines = playablePatchlines(path, patchlines)
+		if len(patchlines) == 0 {
+			continue
+		}
+
+		candidates = append(candidates, InstallCandidate{
+			Path:       path,
+			Source:     probe.source,
+			Patchlines: patchlines,
+		})
+	}
+
+	return candidates
+}
+
+// playablePatchlines returns the patchlines whose latest build has a client binary, so that
+// leftovers of uninstalled or half-downloaded patchlines are not offered
+func playablePatchlines(installPath string, patchlines []Patchline) []Patchline {
+	playable := []Patchline{}
+	for _, patchline := range patchlines {
+		gameDir := filepath.Join(GetPackageDir(installPath, patchline.Name), "game", DefaultBuild)
+		if info, err := os.Stat(ClientBinaryPath(gameDir)); err == nil && !info.IsDir() {
+			playable = append(playable, patchline)
+		}
+	}
+	return playable
+}
+
+type installProbe struct {
+	path   string
+	source string
+}
+
+// installProbes returns the locations to check on the current platform
+func installProbes() []installProbe {
+	var probes []installProbe
+	add := func(base, source string) {
+		if base == "" {
+			return
+		}
+		for _, name := range installDirNames {
+			probes = append(probes, installProbe{path: filepath.Join(base, name), source: source})
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "windows":
+		add(os.Getenv("APPDATA"), "default")
+		add(os.Getenv("LOCALAPPDATA"), "default")
+		add(os.Getenv("ProgramFiles"), "program-files")
+		add(os.Getenv("ProgramFiles(x86)"), "program-files")
+	case "darwin":
+		if home != "" {
+			add(filepath.Join(home, "Library", "Application Support"), "default")
+		}
+		add("/Applications", "applications")
+	default:
+		// XDG data home, falling back to ~/.local/share
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" && home != "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		add(dataHome, "xdg-data")
+
+		// System-wide XDG data dirs
+		dataDirs := os.Getenv("XDG_DATA_DIRS")
+		if dataDirs == "" {
+			dataDirs = "/usr/local/share:/usr/share"
+		}
+		for _, dir := range strings.Split(dataDirs, ":") {
+			add(dir, "xdg-data")
+		}
+
+		// Flatpak apps keep their data under ~/.var/app/<app-id>/data
+		if home != "" {
+			flatpakDirs, _ := filepath.Glob(filepath.Join(home, ".var", "app", "*", "data"))
+			for _, dir := range flatpakDirs {
+				add(dir, "flatpak")
+			}
+		}
+
+		if home != "" {
+			add(home, "home")
+		}
+	}
+
+	return probes
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default patchline and build of the official installation
+const (
+	DefaultPatchline = "release"
+	DefaultBuild     = "latest"
+)
+
+// Patchline is a release channel of the official installation with its installed builds
+type Patchline struct {
+	Name   string   `json:"name"`
+	Builds []string `json:"builds"`
+}
+
+// GameBuild is a resolved build of the official installation
+type GameBuild struct {
+	Patchline string `json:"patchline"`
+	Build     string `json:"build"`
+	GameDir   string `json:"gameDir"`
+	JREDir    string `json:"jreDir"`
+}
+
+// GetPackageDir returns the package directory of a patchline, e.g. install/release/package
+func GetPackageDir(installPath, patchline string) string {
+	return filepath.Join(installPath, "install", patchline, "package")
+}
+
+// ScanPatchlines returns every patchline and build found in the official installation
+func ScanPatchlines(installPath string) ([]Patchline, error) {
+	entries, err := os.ReadDir(filepath.Join(installPath, "install"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Patchline{}, nil
+		}
+		return nil, err
+	}
+
+	patchlines := []Patchline{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		builds, err := os.ReadDir(filepath.Join(GetPackageDir(installPath, entry.Name()), "game"))
+		if err != nil {
+			continue
+		}
+
+		patchline := Patchline{Name: entry.Name()}
+		for _, build := range builds {
+			if build.IsDir() {
+				patchline.Builds = append(patchline.Builds, build.Name())
+			}
+		}
+		if len(patchline.Builds) == 0 {
+			continue
+		}
+
+		sortBuilds(patchline.Builds)
+		patchlines = append(patchlines, patchline)
+	}
+
+	// Release first, then alphabetically
+	sort.Slice(patchlines, func(i, j int) bool {
+		if (patchlines[i].Name == DefaultPatchline) != (patchlines[j].Name == DefaultPatchline) {
+			return patchlines[i].Name == DefaultPatchline
+		}
+		return patchlines[i].Name < patchlines[j].Name
+	})
+
+	return patchlines, nil
+}
+
+// sortBuilds orders builds as "latest" first, then numbered builds newest first, then anything else
+func sortBuilds(builds []string) {
+	rank := func(build string) (int, int) {
+		if build == DefaultBuild {
+			return 0, 0
+		}
+		if n, err := strconv.Atoi(build); err == nil {
+			return 1, -n
+		}
+		return 2, 0
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		ri, ni := rank(builds[i])
+		rj, nj := rank(builds[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if ni != nj {
+			return ni < nj
+		}
+		return builds[i] < builds[j]
+	})
+}
+
+// ResolveBuild resolves a patchline and build of the official installation.
+// Empty names select the defaults. The JRE of the same build is preferred, falling back to the latest JRE.
+func ResolveBuild(installPath, patchline, build string) (*GameBuild, error) {
+	if patchline == "" {
+		patchline = DefaultPatchline
+	}
+	if build == "" {
+		build = DefaultBuild
+	}
+	if !isPlainName(patchline) || !isPlainName(build) {
+		return nil, fmt.Errorf("invalid patchline or build: %s/%s", patchline, build)
+	}
+
+	packageDir := GetPackageDir(installPath, patchline)
+	gameDir := filepath.Join(packageDir, "game", build)
+	if _, err := os.Stat(gameDir); err != nil {
+		return nil, fmt.Errorf("build %s of patchline %s not found at %s", build, patchline, gameDir)
+	}
+
+	jreDir := filepath.Join(packageDir, "jre", build)
+	if _, err := os.Stat(jreDir); err != nil {
+		jreDir = filepath.Join(packageDir, "jre", DefaultBuild)
+	}
+
+	return &GameBuild{
+		Patchline: patchline,
+		Build:     build,
+		GameDir:   gameDir,
+		JREDir:    jreDir,
+	}, nil
+}
+
+// IsValidInstall reports whether path looks like an official Hytale installation
+func IsValidInstall(installPath string) bool {
+	patchlines, err := ScanPatchlines(installPath)
+	return err == nil && len(patchlines) > 0
+}
+
+// isPlainName reports whether name is a single path element
+func isPlainName(name string) bool {
+	return name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,456 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/patcher"
+)
+
+// Suffixes of files that internal/patcher leaves next to patched binaries
+const (
+	originalSuffix     = ".original"
+	patchedFlagSuffix  = ".patched_custom"
+	integrityStage     = "verify"
+	manifestSourceNone = "none"
+)
+
+// Integrity problems reported for a file
+const (
+	IssueMissing   = "missing"
+	IssueTruncated = "truncated"
+	IssueModified  = "modified"
+	IssueLeftover  = "leftover"
+)
+
+// Sources of the manifest a report was checked against
+const (
+	ManifestTrusted  = "trusted"
+	ManifestBuilt    = "built"
+	ManifestOutdated = "outdated" // built before the client was replaced, modified files may come from an update
+)
+
+// FileHash is the expected size and hash of an installed file
+type FileHash struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// IntegrityManifest lists the expected files of one build, keyed by "game/..." or "jre/..." paths
+type IntegrityManifest struct {
+	Patchline string `json:"patchline"`
+	Build     string `json:"build"`
+	CreatedAt string `json:"createdAt"`
+	Trusted   bool   `json:"trusted"`
+	// Fingerprint identifies the version of the build a local manifest was built from
+	Fingerprint string              `json:"fingerprint,omitempty"`
+	Files       map[string]FileHash `json:"files"`
+}
+
+// IntegrityIssue is a single problem found during verification
+type IntegrityIssue struct {
+	Path       string `json:"path"`
+	Problem    string `json:"problem"`
+	Restorable bool   `json:"restorable"` // an .original backup exists
+}
+
+// IntegrityReport is the result of verifying an installation against its manifest
+type IntegrityReport struct {
+	Patchline      string           `json:"patchline"`
+	Build          string           `json:"build"`
+	ManifestSource string           `json:"manifestSource"` // trusted, built, outdated or none
+	CheckedFiles   int              `json:"checkedFiles"`
+	Issues         []IntegrityIssue `json:"issues"`
+	OK             bool             `json:"ok"`
+}
+
+// ProgressCallback reports progress in the same shape as the launcher's progress-update event
+type ProgressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)
+
+// manifestPath returns where the manifest of a build is stored
+func manifestPath(installPath string, build *GameBuild, trusted bool) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(installPath)))
+	name := fmt.Sprintf("%s_%s_%s.json", hex.EncodeToString(sum[:4]), build.Patchline, build.Build)
+	if trusted {
+		name = "trusted_" + name
+	}
+	return filepath.Join(env.GetStateDir(), "integrity", name)
+}
+
+// LoadManifest returns the manifest for a build, preferring a trusted one. It returns nil if none exists.
+func LoadManifest(installPath string, build *GameBuild) (*IntegrityManifest, error) {
+	for _, trusted := range []bool{true, false} {
+		data, err := os.ReadFile(manifestPath(installPath, build, trusted))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		var manifest IntegrityManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse integrity manifest: %w", err)
+		}
+		manifest.Trusted = trusted
+		return &manifest, nil
+	}
+	return nil, nil
+}
+
+// saveManifest stores a manifest for a build
+func saveManifest(installPath string, build *GameBuild, manifest *IntegrityManifest) error {
+	path := manifestPath(installPath, build, manifest.Trusted)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ImportTrustedManifest stores a manifest from a trusted source for a build.
+// A trusted manifest takes precedence over one built locally.
+func ImportTrustedManifest(installPath string, build *GameBuild, src string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	var manifest IntegrityManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid integrity manifest: %w", err)
+	}
+	if len(manifest.Files) == 0 {
+		return fmt.Errorf("integrity manifest lists no files")
+	}
+
+	manifest.Patchline = build.Patchline
+	manifest.Build = build.Build
+	manifest.Trusted = true
+	return saveManifest(installPath, build, &manifest)
+}
+
+// EnsureManifest builds and stores a manifest for a build if it has none yet, or if its local
+// manifest was built before the official launcher updated the build in place. A build with
+// missing or truncated files is damaged rather than updated, so its manifest is kept.
+func EnsureManifest(installPath string, build *GameBuild) error {
+	existing, err := LoadManifest(installPath, build)
+	if err != nil {
+		return err
+	}
+	if existing != nil && (existing.Trusted || existing.Fingerprint == buildFingerprint(build)) {
+		return nil
+	}
+	if existing != nil {
+		issues, _ := compareManifest(build, existing, nil)
+		for _, issue := range issues {
+			if issue.Problem == IssueMissing || issue.Problem == IssueTruncated {
+				fmt.Printf("Warning: keeping the integrity manifest of %s/%s, %s is %s\n", build.Patchline, build.Build, issue.Path, issue.Problem)
+				return nil
+			}
+		}
+	}
+
+	fmt.Printf("Building integrity manifest for %s/%s...\n", build.Patchline, build.Build)
+	manifest, err := BuildManifest(build, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := saveManifest(installPath, build, manifest); err != nil {
+		return err
+	}
+
+	fmt.Printf("Integrity manifest saved (%d files)\n", len(manifest.Files))
+	return nil
+}
+
+// BuildManifest hashes every file of a build. Patched binaries are recorded from their .original backups.
+func BuildManifest(build *GameBuild, progress ProgressCallback) (*IntegrityManifest, error) {
+	files, total, err := listBuildFiles(build)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &IntegrityManifest{
+		Patchline:   build.Patchline,
+		Build:       build.Build,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		Fingerprint: buildFingerprint(build),
+		Files:       map[string]FileHash{},
+	}
+
+	var done int64
+	for _, f := range files {
+		if isPatcherArtifact(f.rel) {
+			continue
+		}
+
+		// Hash the unpatched original so that patched binaries show up as modified
+		source := f.path
+		if _, err := os.Stat(f.path + originalSuffix); err == nil {
+			source = f.path + originalSuffix
+		}
+
+		hash, size, err := hashFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", f.rel, err)
+		}
+		manifest.Files[f.rel] = FileHash{Size: size, SHA256: hash}
+
+		done += f.size
+		reportProgress(progress, "Building integrity manifest...", f.rel, done, total)
+	}
+
+	return manifest, nil
+}
+
+// VerifyInstall checks a build against its manifest and reports missing, truncated or
+// modified files, as well as leftover patcher artifacts
+func VerifyInstall(installPath string, build *GameBuild, progress ProgressCallback) (*IntegrityReport, error) {
+	report := &IntegrityReport{
+		Patchline:      build.Patchline,
+		Build:          build.Build,
+		ManifestSource: manifestSourceNone,
+		Issues:         []IntegrityIssue{},
+	}
+
+	manifest, err := LoadManifest(installPath, build)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leftover patcher artifacts are reported even without a manifest
+	files, _, err := listBuildFiles(build)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if isPatcherArtifact(f.rel) {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Path:       f.rel,
+				Problem:    IssueLeftover,
+				Restorable: strings.HasSuffix(f.rel, originalSuffix),
+			})
+		}
+	}
+
+	if manifest != nil {
+		report.ManifestSource = ManifestBuilt
+		if manifest.Trusted {
+			report.ManifestSource = ManifestTrusted
+		} else if manifest.Fingerprint != buildFingerprint(build) {
+			// Files are still checked: missing and truncated files are damage either way
+			fmt.Printf("Integrity manifest of %s/%s predates the installed client and is rebuilt after the next clean exit if no files are missing\n", build.Patchline, build.Build)
+			report.ManifestSource = ManifestOutdated
+		}
+
+		issues, checked := compareManifest(build, manifest, progress)
+		report.Issues = append(report.Issues, issues...)
+		report.CheckedFiles = checked
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Path < report.Issues[j].Path
+	})
+	report.OK = len(report.Issues) == 0
+	reportProgress(progress, "Verification complete", "", 1, 1)
+
+	return report, nil
+}
+
+// compareManifest checks every file of a manifest and returns the missing, truncated and
+// modified files and how many files were checked
+func compareManifest(build *GameBuild, manifest *IntegrityManifest, progress ProgressCallback) ([]IntegrityIssue, int) {
+	paths := make([]string, 0, len(manifest.Files))
+	var total int64
+	for rel, expected := range manifest.Files {
+		paths = append(paths, rel)
+		total += expected.Size
+	}
+	sort.Strings(paths)
+
+	issues := []IntegrityIssue{}
+	var done int64
+	for _, rel := range paths {
+		expected := manifest.Files[rel]
+		path := buildFilePath(build, rel)
+		_, backupErr := os.Stat(path + originalSuffix)
+		restorable := backupErr == nil
+
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			issues = append(issues, IntegrityIssue{Path: rel, Problem: IssueMissing, Restorable: restorable})
+		case info.Size() < expected.Size:
+			issues = append(issues, IntegrityIssue{Path: rel, Problem: IssueTruncated, Restorable: restorable})
+		default:
+			hash, _, err := hashFile(path)
+			if err != nil || hash != expected.SHA256 {
+				issues = append(issues, IntegrityIssue{Path: rel, Problem: IssueModified, Restorable: restorable})
+			}
+		}
+
+		done += expected.Size
+		reportProgress(progress, "Verifying game files...", rel, done, total)
+	}
+	return issues, len(paths)
+}
+
+// RestoreOriginals restores every binary of a build that has an .original backup and removes
+// the backup and patch flag. It returns the restored files.
+func RestoreOriginals(build *GameBuild, progress ProgressCallback) ([]string, error) {
+	files, _, err := listBuildFiles(build)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, f := range files {
+		if strings.HasSuffix(f.rel, originalSuffix) {
+			backups = append(backups, f.rel)
+		}
+	}
+
+	p := patcher.NewClientPatcher("")
+	restored := []string{}
+	for i, backup := range backups {
+		rel := strings.TrimSuffix(backup, originalSuffix)
+		path := buildFilePath(build, rel)
+
+		reportProgress(progress, "Restoring original files...", rel, int64(i), int64(len(backups)))
+		if err := p.RestoreBinary(path); err != nil {
+			return restored, fmt.Errorf("failed to restore %s: %w", rel, err)
+		}
+		if err := os.Remove(path + originalSuffix); err != nil {
+			return restored, fmt.Errorf("failed to remove backup of %s: %w", rel, err)
+		}
+		restored = append(restored, rel)
+	}
+
+	// Flags without a backup cannot be restored, but they are stale either way
+	for _, f := range files {
+		if strings.HasSuffix(f.rel, patchedFlagSuffix) {
+			os.Remove(f.path)
+		}
+	}
+
+	reportProgress(progress, "Restore complete", "", 1, 1)
+	return restored, nil
+}
+
+type buildFile struct {
+	rel  string // "game/..." or "jre/..." with forward slashes
+	path string
+	size int64
+}
+
+// listBuildFiles lists every regular file of a build's game and JRE directories
+func listBuildFiles(build *GameBuild) ([]buildFile, int64, error) {
+	var files []buildFile
+	var total int64
+
+	roots := map[string]string{"game": build.GameDir, "jre": build.JREDir}
+	for _, prefix := range []string{"game", "jre"} {
+		root := roots[prefix]
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, buildFile{rel: prefix + "/" + filepath.ToSlash(rel), path: path, size: info.Size()})
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return files, total, nil
+}
+
+// buildFingerprint identifies the installed version of a build by the size and modification
+// time of its client executable, which every update replaces. It ignores the other files, so
+// that deleting them is reported as damage instead of looking like an update. The patcher's
+// .original backup stands in for a patched client.
+func buildFingerprint(build *GameBuild) string {
+	client := ClientBinaryPath(build.GameDir)
+	if _, err := os.Stat(client + originalSuffix); err == nil {
+		client += originalSuffix
+	}
+	info, err := os.Stat(client)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%d", info.Size(), info.ModTime().UnixNano())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// buildFilePath maps a manifest path back to the file system
+func buildFilePath(build *GameBuild, rel string) string {
+	root := build.GameDir
+	if strings.HasPrefix(rel, "jre/") {
+		root = build.JREDir
+	}
+	_, inner, _ := strings.Cut(rel, "/")
+	return filepath.Join(root, filepath.FromSlash(inner))
+}
+
+// isPatcherArtifact reports whether a file was left behind by internal/patcher
+func isPatcherArtifact(rel string) bool {
+	return strings.HasSuffix(rel, originalSuffix) || strings.HasSuffix(rel, patchedFlagSuffix)
+}
+
+// hashFile returns the SHA-256 and size of a file
+func hashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// reportProgress forwards byte-based progress to the callback, if any
+func reportProgress(progress ProgressCallback, message, currentFile string, done, total int64) {
+	if progress == nil {
+		return
+	}
+	percent := 100.0
+	if total > 0 {
+		percent = float64(done) / float64(total) * 100
+	}
+	progress(integrityStage, percent, message, currentFile, "", done, total)
+}
@@ -11,238 +11,195 @@ import (
 	"HyPrism/internal/auth"
 )
 
-// LaunchInstance launches using official Hytale installation
-func LaunchInstance(gameInstallPath string) error {
-	// Require authentication - no offline mode
-	session, err := auth.GetValidSession()
-	if err != nil || session == nil {
-		return fmt.Errorf("authentication required: please log in with your Hytale account")
-	}
-	
-	fmt.Printf("Launching with authenticated account: %s (UUID: %s)\n", session.Username, session.UUID)
-	
-	// Use official Hytale installation paths
-	gameDir := filepath.Join(gameInstallPath, "install", "release", "package", "game", "latest")
-	
-// Verify client exists
-var clientPath string
-switch runtime.GOOS {
-case "darwin":
-	clientPath = filepath.Join(gameDir, "Client", "Hytale.app", "Contents", "MacOS", "HytaleClient")
-case "windows":
-	clientPath = filepath.Join(gameDir, "Client", "HytaleClient.exe")
-default:
-	clientPath = filepath.Join(gameDir, "Client", "HytaleClient")
+// LaunchCommand describes exactly how the game client is started
+type LaunchCommand struct {
+	Path        string   `json:"path"`        // executable to run (the wrapper, if one is configured)
+	Args        []string `json:"args"`        // arguments, excluding Path
+	Dir         string   `json:"dir"`         // working directory
+	Env         []string `json:"env"`         // variables set on top of the launcher's environment
+	ClientPath  string   `json:"clientPath"`  // game client executable
+	UserDataDir string   `json:"userDataDir"` // client user data directory
+	Patchline   string   `json:"patchline"`
+	Build       string   `json:"build"`
 }
 
-if _, err := os.Stat(clientPath); err != nil {
-	return fmt.Errorf("game client not found at %s: %w", clientPath, err)
-}
+// LaunchInstance starts a client prepared by PrepareLaunch.
+// The started client is supervised by manager.
+func LaunchInstance(manager *SessionManager, launch *LaunchCommand) error {
+	fmt.Printf("=== LAUNCH ===\n")
+	fmt.Printf("Build: %s/%s\n", launch.Patchline, launch.Build)
+	fmt.Printf("Client: %s\n", launch.ClientPath)
+	fmt.Printf("UserData: %s\n", launch.UserDataDir)
+	fmt.Printf("Command: %s\n", strings.Join(append([]string{launch.Path}, RedactArgs(launch.Args)...), " "))
+	for _, kv := range RedactEnv(launch.Env) {
+		fmt.Printf("Env: %s\n", kv)
+	}
+	fmt.Printf("================\n")
+
+	cmd := exec.Command(launch.Path, launch.Args...)
+	cmd.Dir = launch.Dir
+	cmd.Env = applyEnv(os.Environ(), launch.Env)
+	if runtime.GOOS == "windows" {
+		cmd.SysProcAttr = getWindowsSysProcAttr()
+	}
 
-// UserData under official installation by default
-userDataDir := filepath.Join(gameInstallPath, "UserData")
-_ = os.MkdirAll(userDataDir, 0755)
-
-// Set up Java path from official installation
-var jrePath string
-jreRoot := filepath.Join(gameInstallPath, "install", "release", "package", "jre", "latest")
-
-switch runtime.GOOS {
-case "darwin":
-	jrePath = filepath.Join(jreRoot, "bin", "java")
-case "windows":
-	jrePath = filepath.Join(jreRoot, "bin", "java.exe")
-default:
-	jrePath = filepath.Join(jreRoot, "bin", "java")
+	if _, err := manager.Start(cmd, launch.UserDataDir); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-if _, err := os.Stat(jrePath); err != nil {
-	return fmt.Errorf("Java not found at %s: %w", jrePath, err)
+// ClientBinaryPath returns the path of the client executable in a build's game directory
+func ClientBinaryPath(gameDir string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(gameDir, "Client", "Hytale.app", "Contents", "MacOS", "HytaleClient")
+	case "windows":
+		return filepath.Join(gameDir, "Client", "HytaleClient.exe")
+	default:
+		return filepath.Join(gameDir, "Client", "HytaleClient")
+	}
 }
 
-fmt.Printf("=== LAUNCH ===\n")
-fmt.Printf("Game dir: %s\n", gameDir)
-fmt.Printf("UserData: %s\n", userDataDir)
-fmt.Printf("JRE: %s\n", jrePath)
-fmt.Printf("================\n")
+// PrepareLaunch runs every pre-launch check and builds the client command line without starting it
+func PrepareLaunch(gameInstallPath string, session *auth.AuthSession, opts LaunchOptions) (*LaunchCommand, error) {
+	if err := ValidateLaunchOptions(opts); err != nil {
+		return nil, err
+	}
 
-// Prepare auth args
-playerName := session.Username
-uuid := session.UUID
-sessionToken := session.SessionToken
-identityToken := session.IdentityToken
+	// Use the selected patchline and build of the official installation
+	gameBuild, err := ResolveBuild(gameInstallPath, opts.Patchline, opts.Build)
+	if err != nil {
+		return nil, err
+	}
+	gameDir := gameBuild.GameDir
 
-authMode := "authenticated"
+	// Verify client exists
+	clientPath := ClientBinaryPath(gameDir)
 
-var cmd *exec.Cmd
-if runtime.GOOS == "darwin" {
-	appBundlePath := filepath.Join(gameDir, "Client", "Hytale.app")
-	args := []string{
-		"--args",
-		"--app-dir", gameDir,
-		"--user-dir", userDataDir,
-		"--java-exec", jrePath,
-		"--auth-mode", authMode,
-		"--uuid", uuid,
-	}
-	args = append(args, "--name", playerName, "--session-token", sessionToken, "--identity-token", identityToken)
-	cmd = exec.Command("open", appBundlePath)
-	cmd.Args = append(cmd.Args, args...)
-} else if runtime.GOOS == "windows" {
-	// Windows - launch directly without special working directory
-	args := []string{
-		"--app-dir", gameDir,
-		"--user-dir", userDataDir,
-		"--java-exec", jrePath,
-		"--auth-mode", authMode,
-		"--uuid", uuid,
-	}
-	args = append(args, "--name", playerName, "--session-token", sessionToken, "--identity-token", identityToken)
-	cmd = exec.Command(clientPath, args...)
-	cmd.SysProcAttr = getWindowsSysProcAttr()
-} else {
-	// Linux - must set LD_LIBRARY_PATH to find SDL3_image and other native libraries
-	clientDir := filepath.Join(gameDir, "Client")
-	args := []string{
-		"--app-dir", gameDir,
-		"--user-dir", userDataDir,
-		"--java-exec", jrePath,
-		"--auth-mode", authMode,
-		"--uuid", uuid,
-	}
-	if authMode == "authenticated" && sessionToken != "" {
-		args = append(args, "--name", playerName, "--session-token", sessionToken, "--identity-token", identityToken)
-	} else {
-		args = append(args, "--name", playerName)
-	}
-	cmd = exec.Command(clientPath, args...)
-	// Preserve LD_LIBRARY_PATH with Client directory first
-	existingLdPath := os.Getenv("LD_LIBRARY_PATH")
-	newLdPath := clientDir
-	if existingLdPath != "" {
-		newLdPath = fmt.Sprintf("%s:%s", clientDir, existingLdPath)
+	if _, err := os.Stat(clientPath); err != nil {
+		return nil, fmt.Errorf("game client not found at %s: %w", clientPath, err)
 	}
-	cmd.Env = append(os.Environ(), fmt.Sprintf("LD_LIBRARY_PATH=%s", newLdPath))
+
+	// UserData under official installation by default
+	userDataDir := filepath.Join(gameInstallPath, "UserData")
+	_ = os.MkdirAll(userDataDir, 0755)
+
+	// Set up Java path from official installation
+	jrePath := filepath.Join(gameBuild.JREDir, "bin", "java")
+	if runtime.GOOS == "windows" {
+		jrePath = filepath.Join(gameBuild.JREDir, "bin", "java.exe")
 	}
-	
-cmd.Dir = gameInstallPath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start game: %w", err)
+	if _, err := os.Stat(jrePath); err != nil {
+		return nil, fmt.Errorf("Java not found at %s: %w", jrePath, err)
 	}
 
-	gameProcess = cmd.Process
-	gameRunning = true
-	
-	go func() {
-		cmd.Wait()
-		gameProcess = nil
-		gameRunning = false
-	}()
+	// macOS runs the bundle executable directly so the session manager owns the client process
+	// (launching through "open" would hand the process off to launchd)
+	args := []string{
+		"--app-dir", gameDir,
+		"--user-dir", userDataDir,
+		"--java-exec", jrePath,
+		"--auth-mode", "authenticated",
+		"--uuid", session.UUID,
+		"--name", session.Username,
+		"--session-token", session.SessionToken,
+		"--identity-token", session.IdentityToken,
+	}
 
-	return nil
-}
+	extraArgs, _ := SplitCommandLine(opts.ExtraArgs)
+	args = append(args, extraArgs...)
 
-var gameProcess *os.Process
-var gameRunning bool
-
-// KillGame terminates the running game process
-func KillGame() error {
-	if !gameRunning {
-		return fmt.Errorf("no game process running")
-	}
-	
-	// Try to kill by process reference first
-	if gameProcess != nil {
-		err := gameProcess.Kill()
-		if err == nil {
-			gameProcess = nil
-			gameRunning = false
-			fmt.Println("Game process terminated")
-			return nil
+	var env []string
+	if runtime.GOOS == "linux" {
+		// The display backend goes first so an explicit SDL_VIDEODRIVER in the user's variables wins
+		if driver := sdlVideoDriver(opts.DisplayBackend); driver != "" {
+			env = append(env, "SDL_VIDEODRIVER="+driver)
 		}
 	}
-	
-	// If that fails, try to find and kill by name
-	if runtime.GOOS == "darwin" {
-		exec.Command("pkill", "-f", "Hytale").Run()
-	} else if runtime.GOOS == "windows" {
-		cmd := exec.Command("taskkill", "/F", "/IM", "HytaleClient.exe")
-		cmd.SysProcAttr = getWindowsSysProcAttr()
-		cmd.Run()
-	} else {
-		exec.Command("pkill", "-f", "HytaleClient").Run()
-	}
-	
-	gameProcess = nil
-	gameRunning = false
-	fmt.Println("Game process terminated")
-	return nil
-}
+	for _, key := range sortedKeys(opts.Env) {
+		env = setEnv(env, key, opts.Env[key])
+	}
 
-// IsGameRunning checks if the game process is still running
-func IsGameRunning() bool {
-	// Active check for the game process by name
-	var isRunning bool
-	
-	if runtime.GOOS == "darwin" {
-		// Check for Hytale process on macOS
-		out, err := exec.Command("pgrep", "-f", "Hytale").Output()
-		isRunning = err == nil && len(out) > 0
-	} else if runtime.GOOS == "windows" {
-		// Check for HytaleClient.exe on Windows using WMI (no window flash)
-		isRunning = isWindowsProcessRunning("HytaleClient.exe")
-	} else {
-		// Linux
-		out, err := exec.Command("pgrep", "-f", "HytaleClient").Output()
-		isRunning = err == nil && len(out) > 0
-	}
-	
-	// Update global state
-	gameRunning = isRunning
-	if !isRunning {
-		gameProcess = nil
-	}
-	
-	return isRunning
-}
+	if runtime.GOOS == "linux" {
+		// Linux - must set LD_LIBRARY_PATH to find SDL3_image and other native libraries
+		// Preserve LD_LIBRARY_PATH with Client directory first
+		clientDir := filepath.Join(gameDir, "Client")
+		existingLdPath, ok := lookupEnv(env, "LD_LIBRARY_PATH")
+		if !ok {
+			existingLdPath = os.Getenv("LD_LIBRARY_PATH")
+		}
+		newLdPath := clientDir
+		if existingLdPath != "" {
+			newLdPath = fmt.Sprintf("%s:%s", clientDir, existingLdPath)
+		}
+		env = setEnv(env, "LD_LIBRARY_PATH", newLdPath)
+	}
 
-// WaitForGameExit waits for the game to exit and returns
-func WaitForGameExit() {
-	if !gameRunning {
-		return
+	launch := &LaunchCommand{
+		Path:        clientPath,
+		Args:        args,
+		Dir:         gameInstallPath,
+		Env:         env,
+		ClientPath:  clientPath,
+		UserDataDir: userDataDir,
+		Patchline:   gameBuild.Patchline,
+		Build:       gameBuild.Build,
 	}
-	
-	// Poll until the game is no longer running
-	for IsGameRunning() {
-		// Sleep briefly to avoid busy-waiting
-		// The IsGameRunning function already checks the actual process
+
+	// Run the client through the wrapper prefix, e.g. "gamemoderun mangohud"
+	if wrapper, _ := SplitCommandLine(opts.Wrapper); len(wrapper) > 0 {
+		wrapperPath, err := exec.LookPath(wrapper[0])
+		if err != nil {
+			return nil, fmt.Errorf("wrapper command %q not found: %w", wrapper[0], err)
+		}
+		launch.Path = wrapperPath
+		launch.Args = append(append(wrapper[1:len(wrapper):len(wrapper)], clientPath), args...)
 	}
-	
-	gameProcess = nil
-	gameRunning = false
-}
 
-// GetGameLogs returns the game log file content
-func GetGameLogs() (string, error) {
-	// Game logs are stored in the official Hytale installation's UserData folder
-	return "Game logs are stored in your official Hytale installation's UserData folder.\nPlease check your Hytale installation directory for logs.", nil
+	return launch, nil
 }
 
-
-// setSDLVideoDriver sets the SDL_VIDEODRIVER environment variable for Linux
-func setSDLVideoDriver(cmd *exec.Cmd) {
-	if runtime.GOOS != "linux" {
-		return
+// sdlVideoDriver returns the SDL_VIDEODRIVER value for the display backend, or "" to leave SDL's default
+func sdlVideoDriver(backend string) string {
+	switch backend {
+	case DisplayBackendWayland:
+		return "wayland"
+	case DisplayBackendX11:
+		return "x11"
 	}
 
-	// Check if running under Wayland
+	// Auto - prefer Wayland when running under a Wayland session, with X11 as fallback
 	waylandDisplay := os.Getenv("WAYLAND_DISPLAY")
 	xdgSession := os.Getenv("XDG_SESSION_TYPE")
 
 	if waylandDisplay != "" || strings.ToLower(xdgSession) == "wayland" {
-		cmd.Env = append(os.Environ(), "SDL_VIDEODRIVER=wayland,x11")
+		return "wayland,x11"
+	}
+	return ""
+}
+
+// sensitiveArgs are launch flags whose values must never be logged
+var sensitiveArgs = map[string]bool{
+	"--session-token":  true,
+	"--identity-token": true,
+}
+
+// RedactArgs returns a copy of the launch arguments with token values masked
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && sensitiveArgs[args[i-1]] {
+			redacted[i] = "<redacted>"
+			continue
+		}
+		if name, _, ok := strings.Cut(arg, "="); ok && sensitiveArgs[name] {
+			redacted[i] = name + "=<redacted>"
+			continue
+		}
+		redacted[i] = arg
 	}
+	return redacted
 }
@@ -2,15 +2,21 @@
 
 package game
 
-import "syscall"
+import (
+	"os"
+	"syscall"
+)
 
 // getWindowsSysProcAttr returns nil on non-Windows platforms
 func getWindowsSysProcAttr() *syscall.SysProcAttr {
 	return nil
 }
 
-// isWindowsProcessRunning is a stub for non-Windows platforms
-// It should never be called on Unix systems
-func isWindowsProcessRunning(processName string) bool {
-	return false
+// exitSignal returns the name of the signal that terminated the process, if any
+func exitSignal(state *os.ProcessState) string {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	return status.Signal().String()
 }
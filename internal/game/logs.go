@@ -0,0 +1,358 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"HyPrism/internal/env"
+)
+
+// EventGameLog carries a single line of live game output to the frontend
+const EventGameLog = "game-log"
+
+const (
+	// maxGameLogFiles is how many session logs are kept before the oldest are deleted
+	maxGameLogFiles = 20
+	// maxGameLogAge is how long session logs are kept
+	maxGameLogAge = 30 * 24 * time.Hour
+	// maxGameLogRead caps how much of a session log is returned to the frontend
+	maxGameLogRead = 4 * 1024 * 1024
+
+	// maxLogLineSize is how much output without a newline is emitted as one line
+	maxLogLineSize = 64 * 1024
+
+	// maxScrubSize is the largest log file ScrubLogs rewrites
+	maxScrubSize = 64 * 1024 * 1024
+
+	gameLogPrefix = "game_"
+	gameLogSuffix = ".log"
+)
+
+// GameLogLine is a line of game output emitted while the game runs
+type GameLogLine struct {
+	SessionID string `json:"sessionId"`
+	Stream    string `json:"stream"` // stdout or stderr
+	Line      string `json:"line"`
+}
+
+// GameLogInfo describes a stored session log
+type GameLogInfo struct {
+	SessionID string `json:"sessionId"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	UpdatedAt string `json:"updatedAt"` // RFC 3339
+	Running   bool   `json:"running"`
+}
+
+// GetGameLogsDir returns the directory where per-session game logs are written
+func GetGameLogsDir() string {
+	return filepath.Join(env.GetLogsDir(), "game")
+}
+
+// gameLogPath returns the log file path for a session
+func gameLogPath(sessionID string) string {
+	return filepath.Join(GetGameLogsDir(), gameLogPrefix+sessionID+gameLogSuffix)
+}
+
+// sessionLog writes game output to the session log file and forwards complete lines
+type sessionLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	onLine func(stream, line string)
+}
+
+// createSessionLog creates the log file for a new session and prunes old logs
+func createSessionLog(sessionID string, onLine func(stream, line string)) (*sessionLog, error) {
+	if err := os.MkdirAll(GetGameLogsDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create game logs directory: %w", err)
+	}
+
+	if err := pruneGameLogs(maxGameLogFiles-1, maxGameLogAge); err != nil {
+		fmt.Printf("Warning: failed to prune game logs: %v\n", err)
+	}
+
+	file, err := os.OpenFile(gameLogPath(sessionID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game log: %w", err)
+	}
+
+	return &sessionLog{file: file, onLine: onLine}, nil
+}
+
+// Path returns the log file path
+func (l *sessionLog) Path() string {
+	return l.file.Name()
+}
+
+// Stream returns a writer for one of the process output streams
+func (l *sessionLog) Stream(name string) io.Writer {
+	return &streamWriter{log: l, name: name}
+}
+
+// Close flushes and closes the log file
+func (l *sessionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// streamWriter splits one output stream into lines for the live tail
+type streamWriter struct {
+	log     *sessionLog
+	name    string
+	partial []byte
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.log.mu.Lock()
+	n, err := w.log.file.Write(p)
+	w.log.mu.Unlock()
+	if err != nil {
+		return n, err
+	}
+
+	if w.log.onLine == nil {
+		return n, nil
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.partial[:idx]), "\r")
+		w.partial = w.partial[idx+1:]
+		w.log.onLine(w.name, line)
+	}
+
+	// Output without newlines, e.g. a progress bar, is passed on in chunks instead of growing without limit
+	for len(w.partial) >= maxLogLineSize {
+		w.log.onLine(w.name, string(w.partial[:maxLogLineSize]))
+		w.partial = w.partial[maxLogLineSize:]
+	}
+
+	return n, nil
+}
+
+// ListGameLogs returns the stored session logs, newest first
+func ListGameLogs() ([]GameLogInfo, error) {
+	entries, err := os.ReadDir(GetGameLogsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []GameLogInfo{}, nil
+		}
+		return nil, err
+	}
+
+	logs := []GameLogInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, gameLogPrefix) || !strings.HasSuffix(name, gameLogSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, GameLogInfo{
+			SessionID: strings.TrimSuffix(strings.TrimPrefix(name, gameLogPrefix), gameLogSuffix),
+			Path:      filepath.Join(GetGameLogsDir(), name),
+			Size:      info.Size(),
+			UpdatedAt: info.ModTime().Format(time.RFC3339),
+		})
+	}
+
+	// Session IDs are timestamps, so sorting by ID sorts by start time
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].SessionID > logs[j].SessionID
+	})
+
+	return logs, nil
+}
+
+// ReadGameLog returns the content of a session log.
+// An empty sessionID selects the newest session. Very large logs are truncated to their tail.
+func ReadGameLog(sessionID string) (string, error) {
+	if sessionID == "" {
+		logs, err := ListGameLogs()
+		if err != nil {
+			return "", err
+		}
+		if len(logs) == 0 {
+			return "", fmt.Errorf("no game logs found")
+		}
+		sessionID = logs[0].SessionID
+	}
+
+	// Session IDs are generated by us; reject anything that could escape the logs dir
+	if strings.ContainsAny(sessionID, `/\`) || strings.Contains(sessionID, "..") {
+		return "", fmt.Errorf("invalid session id: %s", sessionID)
+	}
+
+	file, err := os.Open(gameLogPath(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no log found for session %s", sessionID)
+		}
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	truncated := false
+	if info.Size() > maxGameLogRead {
+		if _, err := file.Seek(info.Size()-maxGameLogRead, io.SeekStart); err != nil {
+			return "", err
+		}
+		truncated = true
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	if truncated {
+		return "[... earlier output truncated ...]\n" + string(data), nil
+	}
+	return string(data), nil
+}
+
+// pruneGameLogs deletes session logs older than maxAge and keeps at most keep files
+func pruneGameLogs(keep int, maxAge time.Duration) error {
+	logs, err := ListGameLogs()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for i, log := range logs {
+		updatedAt, _ := time.Parse(time.RFC3339, log.UpdatedAt)
+		if i < keep && updatedAt.After(cutoff) {
+			continue
+		}
+		if err := os.Remove(log.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// launcherNameFields matches the places where the launcher writes a player name into its
+// logs: the --name game argument and its own account messages. Names are only scrubbed
+// there, because a short name may also be a common word elsewhere in a log.
+const launcherNameFields = `(?i)(--name[ =]"?|session saved for user: |playing as profile |switched to account |authenticated account: |logged in as:? |session of |refresh of |signed out )`
+
+// scrubPattern is a value to remove and what replaces it
+type scrubPattern struct {
+	re   *regexp.Regexp
+	repl []byte
+}
+
+// ScrubLogs removes the IDs and name of a signed-out account from the stored launcher and
+// game logs and crash reports, and from extraFiles such as server crash histories. IDs are
+// replaced everywhere, the name only in launcher-written fields. Binary files are skipped.
+// It returns how many files were changed.
+func ScrubLogs(ids []string, username string, extraFiles ...string) (int, error) {
+	var patterns []scrubPattern
+	for _, id := range ids {
+		if len(id) >= 3 {
+			patterns = append(patterns, scrubPattern{
+				re:   regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(id) + `\b`),
+				repl: []byte("<removed>"),
+			})
+		}
+	}
+	if username != "" {
+		patterns = append(patterns, scrubPattern{
+			re:   regexp.MustCompile(launcherNameFields + regexp.QuoteMeta(username) + `\b`),
+			repl: []byte("${1}<removed>"),
+		})
+	}
+	if len(patterns) == 0 {
+		return 0, nil
+	}
+
+	changed := 0
+	err := filepath.WalkDir(env.GetLogsDir(), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		scrubbed, err := scrubFile(path, patterns)
+		if scrubbed {
+			changed++
+		}
+		return err
+	})
+	if err != nil {
+		return changed, err
+	}
+
+	for _, path := range extraFiles {
+		scrubbed, err := scrubFile(path, patterns)
+		if err != nil && !os.IsNotExist(err) {
+			return changed, err
+		}
+		if scrubbed {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// scrubFile applies patterns to a text file and returns true if it was changed
+func scrubFile(path string, patterns []scrubPattern) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxScrubSize {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return false, nil
+	}
+
+	scrubbed := data
+	for _, p := range patterns {
+		scrubbed = p.re.ReplaceAll(scrubbed, p.repl)
+	}
+	if bytes.Equal(scrubbed, data) {
+		return false, nil
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, scrubbed, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,198 @@
+package game
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Display backends for the client on Linux
+const (
+	DisplayBackendAuto    = "auto"
+	DisplayBackendWayland = "wayland"
+	DisplayBackendX11     = "x11"
+)
+
+// LaunchOptions holds the user-configurable parts of the client launch
+type LaunchOptions struct {
+	ExtraArgs      string            // extra client arguments, shell-style quoting
+	Env            map[string]string // extra environment variables
+	Wrapper        string            // command prefix, e.g. "gamemoderun mangohud"
+	DisplayBackend string            // auto, wayland or x11 (Linux only)
+	Patchline      string            // patchline of the official installation, e.g. "release"
+	Build          string            // build directory within the patchline, e.g. "latest"
+}
+
+// reservedArgs are set by the launcher and cannot be overridden through extra arguments
+var reservedArgs = map[string]bool{
+	"--app-dir":        true,
+	"--user-dir":       true,
+	"--java-exec":      true,
+	"--auth-mode":      true,
+	"--uuid":           true,
+	"--name":           true,
+	"--session-token":  true,
+	"--identity-token": true,
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sensitiveEnvPattern matches variable names whose values are masked in logs
+var sensitiveEnvPattern = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|API_?KEY)`)
+
+// ValidateLaunchOptions checks that the launch options are well-formed and usable
+func ValidateLaunchOptions(opts LaunchOptions) error {
+	args, err := SplitCommandLine(opts.ExtraArgs)
+	if err != nil {
+		return fmt.Errorf("invalid launch arguments: %w", err)
+	}
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		if reservedArgs[name] {
+			return fmt.Errorf("launch argument %s is managed by the launcher and cannot be overridden", name)
+		}
+	}
+
+	for key, value := range opts.Env {
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name: %q", key)
+		}
+		if strings.ContainsAny(value, "\x00\n") {
+			return fmt.Errorf("environment variable %s contains invalid characters", key)
+		}
+	}
+
+	switch opts.DisplayBackend {
+	case "", DisplayBackendAuto, DisplayBackendWayland, DisplayBackendX11:
+	default:
+		return fmt.Errorf("unknown display backend: %q", opts.DisplayBackend)
+	}
+
+	wrapper, err := SplitCommandLine(opts.Wrapper)
+	if err != nil {
+		return fmt.Errorf("invalid wrapper command: %w", err)
+	}
+	if len(wrapper) > 0 {
+		if _, err := exec.LookPath(wrapper[0]); err != nil {
+			return fmt.Errorf("wrapper command %q not found", wrapper[0])
+		}
+	}
+
+	return nil
+}
+
+// SplitCommandLine splits a command line into arguments.
+// Arguments are separated by whitespace; single quotes, double quotes and backslashes work like in a POSIX shell.
+func SplitCommandLine(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// RedactEnv returns a copy of KEY=VALUE pairs with sensitive values masked
+func RedactEnv(env []string) []string {
+	redacted := make([]string, len(env))
+	for i, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if sensitiveEnvPattern.MatchString(key) {
+			redacted[i] = key + "=<redacted>"
+		} else {
+			redacted[i] = kv
+		}
+	}
+	return redacted
+}
+
+// lookupEnv returns the value of key in a list of KEY=VALUE pairs
+func lookupEnv(env []string, key string) (string, bool) {
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// setEnv sets key in a list of KEY=VALUE pairs, replacing any existing value
+func setEnv(env []string, key, value string) []string {
+	for i, kv := range env {
+		if k, _, ok := strings.Cut(kv, "="); ok && k == key {
+			env[i] = key + "=" + value
+			return env
+		}
+	}
+	return append(env, key+"="+value)
+}
+
+// applyEnv applies overrides on top of a base environment
+func applyEnv(base []string, overrides []string) []string {
+	env := append([]string{}, base...)
+	for _, kv := range overrides {
+		key, value, _ := strings.Cut(kv, "=")
+		env = setEnv(env, key, value)
+	}
+	return env
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sessionArgVars maps session-bound launch flags to the variables printed by the launcher's --session-env mode
+var sessionArgVars = map[string]string{
+	"--uuid":           "HYPRISM_UUID",
+	"--name":           "HYPRISM_USERNAME",
+	"--session-token":  "HYPRISM_SESSION_TOKEN",
+	"--identity-token": "HYPRISM_IDENTITY_TOKEN",
+}
+
+// Redacted returns a copy of the launch command that is safe to show or log
+func (l *LaunchCommand) Redacted() *LaunchCommand {
+	redacted := *l
+	redacted.Args = RedactArgs(l.Args)
+	redacted.Env = RedactEnv(l.Env)
+	return &redacted
+}
+
+// CommandLine returns the launch command as a single shell-quoted string with tokens redacted
+func (l *LaunchCommand) CommandLine() string {
+	parts := []string{shellQuote(l.Path)}
+	for _, arg := range RedactArgs(l.Args) {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// WriteLaunchScript writes a POSIX shell script that starts the game exactly as the launcher would.
+// The script asks the launcher command for fresh session tokens each time it runs, so no tokens are stored in it.
+func WriteLaunchScript(l *LaunchCommand, launcher []string, path string) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("launch scripts are not supported on Windows")
+	}
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Generated by HyPrism - starts Hytale with the launcher's current settings.\n")
+	b.WriteString("# Session tokens are requested from HyPrism on every run and are never stored here.\n")
+	b.WriteString("set -e\n\n")
+	var launcherArgs []string
+	for _, arg := range launcher {
+		launcherArgs = append(launcherArgs, shellQuote(arg))
+	}
+	// eval "" succeeds, so the output is captured first to stop on a failed --session-env
+	fmt.Fprintf(&b, "session_env=$(%s --session-env) || exit 1\n", strings.Join(launcherArgs, " "))
+	b.WriteString("eval \"$session_env\"\n\n")
+	fmt.Fprintf(&b, "cd %s\n", shellQuote(l.Dir))
+	for _, kv := range l.Env {
+		key, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", key, shellQuote(value))
+	}
+
+	b.WriteString("\nexec " + shellQuote(l.Path))
+	for i, arg := range l.Args {
+		if i > 0 {
+			if variable, ok := sessionArgVars[l.Args[i-1]]; ok {
+				fmt.Fprintf(&b, " \"$%s\"", variable)
+				continue
+			}
+		}
+		if strings.HasPrefix(arg, "-") {
+			b.WriteString(" \\\n  " + shellQuote(arg))
+		} else {
+			b.WriteString(" " + shellQuote(arg))
+		}
+	}
+	b.WriteString("\n")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0755)
+}
+
+// WriteDesktopEntry writes a .desktop file that starts the game through the launcher's headless --launch mode
+func WriteDesktopEntry(launcher []string, icon, path string) error {
+	var b strings.Builder
+	b.WriteString("[Desktop Entry]\n")
+	b.WriteString("Type=Application\n")
+	b.WriteString("Name=Hytale (HyPrism)\n")
+	b.WriteString("Comment=Launch Hytale with HyPrism's settings\n")
+	var execArgs []string
+	for _, arg := range launcher {
+		execArgs = append(execArgs, desktopExecQuote(arg))
+	}
+	fmt.Fprintf(&b, "Exec=%s --launch\n", strings.Join(execArgs, " "))
+	if icon != "" {
+		fmt.Fprintf(&b, "Icon=%s\n", icon)
+	}
+	b.WriteString("Terminal=false\n")
+	b.WriteString("Categories=Game;\n")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0755)
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,+@%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// desktopExecQuote quotes an Exec= argument as described by the Desktop Entry specification
+func desktopExecQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", `$`, `\\$`, `%`, `%%`)
+	return `"` + replacer.Replace(s) + `"`
+}
@@ -0,0 +1,224 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Session lifecycle events emitted to the frontend
+const (
+	EventGameStarted = "game-started"
+	EventGameExited  = "game-exited"
+)
+
+// ErrNoGameRunning is returned when an operation needs a running game
+var ErrNoGameRunning = errors.New("no game process running")
+
+// SessionInfo describes a single run of the game client
+type SessionInfo struct {
+	ID          string `json:"id"`
+	PID         int    `json:"pid"`
+	StartedAt   string `json:"startedAt"` // RFC 3339
+	EndedAt     string `json:"endedAt,omitempty"`
+	Running     bool   `json:"running"`
+	ExitCode    int    `json:"exitCode"`
+	ExitSignal  string `json:"exitSignal,omitempty"`
+	Error       string `json:"error,omitempty"`
+	LogFile     string `json:"logFile,omitempty"`
+	CrashReport string `json:"crashReport,omitempty"`
+}
+
+// SessionListener receives game lifecycle events (SessionInfo) and live output (GameLogLine)
+type SessionListener func(event string, data interface{})
+
+// SessionManager owns the launched game process and tracks its lifecycle.
+// Only one game session can run at a time.
+type SessionManager struct {
+	mu          sync.Mutex
+	cmd         *exec.Cmd
+	userDataDir string
+	killed      bool
+	current     *SessionInfo
+	last        *SessionInfo
+	done        chan struct{}
+	listener    SessionListener
+}
+
+// NewSessionManager creates a session manager that reports lifecycle events to listener
+func NewSessionManager(listener SessionListener) *SessionManager {
+	return &SessionManager{listener: listener}
+}
+
+// Start starts cmd and supervises it until it exits.
+// The process output is captured into a per-session log file. If the process
+// exits abnormally, a crash report is collected including client logs from userDataDir.
+func (m *SessionManager) Start(cmd *exec.Cmd, userDataDir string) (SessionInfo, error) {
+	m.mu.Lock()
+	if m.current != nil {
+		m.mu.Unlock()
+		return SessionInfo{}, fmt.Errorf("game is already running (PID %d)", m.current.PID)
+	}
+
+	startedAt := time.Now()
+	// Milliseconds keep quick relaunches from sharing a log file and crash report
+	sessionID := startedAt.Format("2006-01-02_15-04-05.000")
+
+	logFile := ""
+	log, err := createSessionLog(sessionID, func(stream, line string) {
+		m.emit(EventGameLog, GameLogLine{SessionID: sessionID, Stream: stream, Line: line})
+	})
+	if err != nil {
+		fmt.Printf("Warning: game output will not be captured: %v\n", err)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	} else {
+		logFile = log.Path()
+		cmd.Stdout = log.Stream("stdout")
+		cmd.Stderr = log.Stream("stderr")
+	}
+
+	if err := cmd.Start(); err != nil {
+		m.mu.Unlock()
+		if log != nil {
+			log.Close()
+		}
+		return SessionInfo{}, fmt.Errorf("failed to start game: %w", err)
+	}
+
+	info := &SessionInfo{
+		ID:        sessionID,
+		PID:       cmd.Process.Pid,
+		StartedAt: startedAt.Format(time.RFC3339),
+		Running:   true,
+		LogFile:   logFile,
+	}
+	done := make(chan struct{})
+	m.cmd = cmd
+	m.userDataDir = userDataDir
+	m.killed = false
+	m.current = info
+	m.done = done
+	started := *info
+	m.mu.Unlock()
+
+	fmt.Printf("Game started (PID %d)\n", started.PID)
+	m.emit(EventGameStarted, started)
+
+	go m.wait(cmd, info, log, done)
+
+	return started, nil
+}
+
+// wait blocks until the process exits and records how it ended
+func (m *SessionManager) wait(cmd *exec.Cmd, info *SessionInfo, log *sessionLog, done chan struct{}) {
+	// Wait also drains the output pipes, so the log is complete once it returns
+	waitErr := cmd.Wait()
+	if log != nil {
+		log.Close()
+	}
+
+	m.mu.Lock()
+	info.Running = false
+	info.EndedAt = time.Now().Format(time.RFC3339)
+	if state := cmd.ProcessState; state != nil {
+		info.ExitCode = state.ExitCode()
+		info.ExitSignal = exitSignal(state)
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		info.Error = waitErr.Error()
+	}
+	killed := m.killed
+	userDataDir := m.userDataDir
+	m.mu.Unlock()
+
+	// A kill requested through the launcher is not a crash
+	if !killed && isAbnormalExit(*info) {
+		reportID, err := WriteCrashReport(*info, cmd.Args[1:], userDataDir)
+		if err != nil {
+			fmt.Printf("Warning: failed to write crash report: %v\n", err)
+		}
+		info.CrashReport = reportID
+	}
+
+	m.mu.Lock()
+	m.cmd = nil
+	m.current = nil
+	m.last = info
+	exited := *info
+	m.mu.Unlock()
+
+	close(done)
+
+	if exited.ExitSignal != "" {
+		fmt.Printf("Game exited (PID %d, signal %s)\n", exited.PID, exited.ExitSignal)
+	} else {
+		fmt.Printf("Game exited (PID %d, code %d)\n", exited.PID, exited.ExitCode)
+	}
+	m.emit(EventGameExited, exited)
+}
+
+// emit forwards an event to the listener, if any
+func (m *SessionManager) emit(event string, data interface{}) {
+	if m.listener != nil {
+		m.listener(event, data)
+	}
+}
+
+// IsRunning reports whether a game session is active
+func (m *SessionManager) IsRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current != nil
+}
+
+// Current returns the running session, or the most recent one if the game has exited.
+// The second return value is false if no game has been launched yet.
+func (m *SessionManager) Current() (SessionInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current != nil {
+		return *m.current, true
+	}
+	if m.last != nil {
+		return *m.last, true
+	}
+	return SessionInfo{}, false
+}
+
+// Kill terminates the running game process
+func (m *SessionManager) Kill() error {
+	m.mu.Lock()
+	cmd := m.cmd
+	if cmd != nil {
+		m.killed = true
+	}
+	m.mu.Unlock()
+
+	if cmd == nil || cmd.Process == nil {
+		return ErrNoGameRunning
+	}
+
+	if err := cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to terminate game: %w", err)
+	}
+
+	fmt.Println("Game process terminated")
+	return nil
+}
+
+// Wait blocks until the running game exits. It returns immediately if no game is running.
+func (m *SessionManager) Wait() {
+	m.mu.Lock()
+	done := m.done
+	running := m.current != nil
+	m.mu.Unlock()
+
+	if running {
+		<-done
+	}
+}
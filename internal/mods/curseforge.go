@@ -568,17 +568,37 @@ func DownloadModToInstance(ctx context.Context, cfMod CurseForgeMod, branch stri
 
 // DownloadModFileToInstance downloads and installs a specific mod file version to an instance
 func DownloadModFileToInstance(ctx context.Context, modID int, fileID int, branch string, version int, progressCallback func(progress float64, message string)) error {
+	mod, tmpPath, err := downloadModFileToDir(ctx, modID, fileID, GetInstanceModsDir(branch, version), progressCallback)
+	if err != nil {
+		return err
+	}
+
+	// The installed version is only replaced once the new file is downloaded
+	if err := replaceMod(*mod, tmpPath, GetInstanceModManifestPath(branch, version)); err != nil {
+		return err
+	}
+
+	if progressCallback != nil {
+		progressCallback(100, fmt.Sprintf("Installed %s v%s successfully!", mod.Name, mod.Version))
+	}
+
+	return nil
+}
+
+// downloadModFileToDir downloads a specific mod file version into a temporary file in modsDir.
+// It returns the manifest entry and the temporary file, which replaceMod moves into place.
+func downloadModFileToDir(ctx context.Context, modID int, fileID int, modsDir string, progressCallback func(progress float64, message string)) (*Mod, string, error) {
 	// Get mod details
 	cfMod, err := GetModDetails(ctx, modID)
 	if err != nil {
-		return fmt.Errorf("failed to get mod details: %w", err)
+		return nil, "", fmt.Errorf("failed to get mod details: %w", err)
 	}
 
 	// Get file details
 	url := fmt.Sprintf("%s/mods/%d/files/%d", curseForgeBaseURL, modID, fileID)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
@@ -586,52 +606,48 @@ func DownloadModFileToInstance(ctx context.Context, modID int, fileID int, branc
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("file not found: %d", fileID)
+		return nil, "", fmt.Errorf("file not found: %d", fileID)
 	}
 
 	var cfResp CurseForgeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&cfResp); err != nil {
-		return err
+		return nil, "", err
 	}
 
 	var modFile ModFile
 	if err := json.Unmarshal(cfResp.Data, &modFile); err != nil {
-		return err
+		return nil, "", err
 	}
 
 	if modFile.DownloadURL == "" {
-		return fmt.Errorf("download not available for this mod file (author disabled distribution)")
+		return nil, "", fmt.Errorf("download not available for this mod file (author disabled distribution)")
 	}
 
-	// First, remove existing version of this mod if installed
-	existingModID := fmt.Sprintf("cf-%d", modID)
-	_ = RemoveInstanceMod(existingModID, branch, version)
-
-	modsDir := GetInstanceModsDir(branch, version)
 	if err := os.MkdirAll(modsDir, 0755); err != nil {
-		return err
+		return nil, "", err
 	}
 
 	destPath := filepath.Join(modsDir, modFile.FileName)
+	tmpPath := filepath.Join(modsDir, "."+modFile.FileName+".download")
 
 	if progressCallback != nil {
 		progressCallback(0, fmt.Sprintf("Downloading %s...", cfMod.Name))
 	}
 
 	// Download the file
-	if err := download.DownloadFile(ctx, modFile.DownloadURL, destPath, func(downloaded, total int64, speed string) {
+	if err := download.DownloadFile(ctx, modFile.DownloadURL, tmpPath, func(downloaded, total int64, speed string) {
 		if progressCallback != nil && total > 0 {
 			progress := float64(downloaded) / float64(total) * 100
 			progressCallback(progress, fmt.Sprintf("Downloading %s... %.1f%%", cfMod.Name, progress))
 		}
 	}); err != nil {
-		os.Remove(destPath)
-		return fmt.Errorf("failed to download mod: %w", err)
+		os.Remove(tmpPath)
+		return nil, "", fmt.Errorf("failed to download mod: %w", err)
 	}
 
 	// Get author name
@@ -652,7 +668,7 @@ func DownloadModFileToInstance(ctx context.Context, modID int, fileID int, branc
 		iconURL = cfMod.Logo.URL
 	}
 
-	// Add to instance manifest
+	// Manifest entry
 	mod := Mod{
 		ID:           fmt.Sprintf("cf-%d", cfMod.ID),
 		Name:         cfMod.Name,
@@ -672,15 +688,7 @@ func DownloadModFileToInstance(ctx context.Context, modID int, fileID int, branc
 		Category:     category,
 	}
 
-	if err := AddInstanceMod(mod, branch, version); err != nil {
-		return err
-	}
-
-	if progressCallback != nil {
-		progressCallback(100, fmt.Sprintf("Installed %s v%s successfully!", cfMod.Name, modFile.DisplayName))
-	}
-
-	return nil
+	return &mod, tmpPath, nil
 }
 
 // CheckInstanceForUpdates checks if any installed mods in an instance have updates
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"HyPrism/internal/env"
+	"HyPrism/internal/util"
 )
 
 // Mod represents a mod
@@ -40,12 +41,17 @@ type ModManifest struct {
 // GetModsDir returns the mods directory path (legacy - for backwards compatibility)
 // Mods should be in UserData/Mods as that's where the game reads them
 func GetModsDir() string {
-	return filepath.Join(env.GetDefaultAppDir(), "UserData", "Mods")
+	return filepath.Join(env.GetDataDir(), "UserData", "Mods")
 }
 
 // GetInstanceModsDir returns the mods directory for a specific instance
+// Falls back to the legacy mods directory when no installation is configured
 func GetInstanceModsDir(branch string, version int) string {
-	return filepath.Join(env.GetInstanceUserDataDir(branch, version), "Mods")
+	userDataDir := env.GetInstanceUserDataDir(branch, version)
+	if userDataDir == "" {
+		return GetModsDir()
+	}
+	return filepath.Join(userDataDir, "Mods")
 }
 
 // GetModManifestPath returns the mod manifest path (legacy)
@@ -72,7 +78,9 @@ func LoadInstanceManifest(branch string, version int) (*ModManifest, error) {
 
 // loadManifestFromPath loads a manifest from a specific path
 func loadManifestFromPath(path string) (*ModManifest, error) {
-	data, err := os.ReadFile(path)
+	data, err := util.ReadFileWithBackup(path, func(data []byte) error {
+		return json.Unmarshal(data, &ModManifest{})
+	})
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &ModManifest{Mods: []Mod{}, Version: "1.0"}, nil
@@ -85,6 +93,20 @@ func loadManifestFromPath(path string) (*ModManifest, error) {
 		return nil, err
 	}
 
+	// Mod files sit next to their manifest; follow them if the folder was moved
+	dir := filepath.Dir(path)
+	for i, m := range manifest.Mods {
+		if m.FilePath == "" || filepath.Dir(m.FilePath) == dir {
+			continue
+		}
+		moved := filepath.Join(dir, filepath.Base(m.FilePath))
+		if _, err := os.Stat(m.FilePath); os.IsNotExist(err) {
+			if _, err := os.Stat(moved); err == nil {
+				manifest.Mods[i].FilePath = moved
+			}
+		}
+	}
+
 	return &manifest, nil
 }
 
@@ -111,7 +133,7 @@ func saveManifestToPath(manifest *ModManifest, path string) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return util.WriteFileWithBackup(path, data, 0644)
 }
 
 // GetInstalledMods returns all installed mods (legacy)
@@ -170,6 +192,72 @@ func AddInstanceMod(mod Mod, branch string, version int) error {
 	return SaveInstanceManifest(manifest, branch, version)
 }
 
+// replaceMod moves a downloaded mod file from tmpPath to mod.FilePath and puts it in the manifest
+// at manifestPath in place of the previous version of the mod. The previous file is kept until
+// the manifest is saved and restored if anything fails.
+func replaceMod(mod Mod, tmpPath string, manifestPath string) error {
+	manifest, err := loadManifestFromPath(manifestPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	index := -1
+	var previous string
+	for i, m := range manifest.Mods {
+		if m.ID == mod.ID {
+			index = i
+			previous = m.FilePath
+		}
+	}
+
+	// Never overwrite a file of another mod or one that HyPrism did not install
+	if filepath.Clean(mod.FilePath) != filepath.Clean(previous) {
+		if _, err := os.Stat(mod.FilePath); err == nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("%s already exists and was not installed for %s", filepath.Base(mod.FilePath), mod.Name)
+		}
+	}
+
+	// Set the previous file aside; it may have the same name as the new one
+	var kept string
+	if previous != "" {
+		if err := os.Rename(previous, previous+".old"); err == nil {
+			kept = previous + ".old"
+		} else if !os.IsNotExist(err) {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to replace %s: %w", filepath.Base(previous), err)
+		}
+	}
+	restore := func() {
+		if kept != "" {
+			os.Rename(kept, previous)
+		}
+	}
+
+	if err := os.Rename(tmpPath, mod.FilePath); err != nil {
+		os.Remove(tmpPath)
+		restore()
+		return fmt.Errorf("failed to install %s: %w", filepath.Base(mod.FilePath), err)
+	}
+
+	if index >= 0 {
+		manifest.Mods[index] = mod
+	} else {
+		manifest.Mods = append(manifest.Mods, mod)
+	}
+	if err := saveManifestToPath(manifest, manifestPath); err != nil {
+		os.Remove(mod.FilePath)
+		restore()
+		return err
+	}
+
+	if kept != "" {
+		os.Remove(kept)
+	}
+	return nil
+}
+
 // RemoveMod removes a mod from manifest and deletes files (legacy)
 func RemoveMod(modID string) error {
 	manifest, err := LoadManifest()
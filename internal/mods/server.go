@@ -0,0 +1,309 @@
+package mods
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"HyPrism/internal/util"
+)
+
+// Install targets of a mod
+const (
+	TargetClient = "client"
+	TargetServer = "server"
+	TargetBoth   = "both"
+)
+
+// ValidateTarget checks an install target
+func ValidateTarget(target string) error {
+	switch target {
+	case TargetClient, TargetServer, TargetBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown install target: %s", target)
+}
+
+// SyncAction is a single change a server mod sync makes
+type SyncAction struct {
+	ModID       string `json:"modId"`
+	Name        string `json:"name"`
+	FileName    string `json:"fileName"`
+	FromVersion string `json:"fromVersion,omitempty"`
+	ToVersion   string `json:"toVersion,omitempty"`
+}
+
+// SyncPlan is the diff between a server's mods and a client mod set
+type SyncPlan struct {
+	Add       []SyncAction `json:"add"`
+	Update    []SyncAction `json:"update"`
+	Remove    []SyncAction `json:"remove"`
+	Unchanged []string     `json:"unchanged"` // mod IDs already in sync
+	Unmanaged []string     `json:"unmanaged"` // jars in the server's mods folder that HyPrism did not install; left untouched
+	Conflicts []SyncAction `json:"conflicts"` // mods not copied because a jar HyPrism did not install has their file name
+	Hash      string       `json:"hash"`      // identifies the changes, so that only a previewed plan is applied
+}
+
+// HasChanges reports whether applying the plan changes anything
+func (p *SyncPlan) HasChanges() bool {
+	return len(p.Add)+len(p.Update)+len(p.Remove) > 0
+}
+
+// getDirManifestPath returns the manifest path of a mods directory
+func getDirManifestPath(modsDir string) string {
+	return filepath.Join(modsDir, "manifest.json")
+}
+
+// GetServerInstalledMods returns the mods HyPrism installed into a server's mods directory
+func GetServerInstalledMods(serverModsDir string) ([]Mod, error) {
+	manifest, err := loadManifestFromPath(getDirManifestPath(serverModsDir))
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Mods, nil
+}
+
+// RemoveServerMod removes a mod from a server's mods directory
+func RemoveServerMod(modID string, serverModsDir string) error {
+	manifest, err := loadManifestFromPath(getDirManifestPath(serverModsDir))
+	if err != nil {
+		return err
+	}
+
+	var newMods []Mod
+	var modToRemove *Mod
+	for _, m := range manifest.Mods {
+		if m.ID == modID {
+			modCopy := m
+			modToRemove = &modCopy
+		} else {
+			newMods = append(newMods, m)
+		}
+	}
+
+	if modToRemove == nil {
+		return fmt.Errorf("mod not found: %s", modID)
+	}
+
+	if modToRemove.FilePath != "" {
+		if err := os.Remove(modToRemove.FilePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	manifest.Mods = newMods
+	return saveManifestToPath(manifest, getDirManifestPath(serverModsDir))
+}
+
+// CopyModToServer copies an installed client mod into a server's mods directory
+func CopyModToServer(mod Mod, serverModsDir string) error {
+	if mod.FilePath == "" {
+		return fmt.Errorf("mod %s has no file", mod.Name)
+	}
+
+	// Servers load every jar in the folder, so the copy is always enabled
+	fileName := strings.TrimSuffix(filepath.Base(mod.FilePath), ".disabled")
+	destPath := filepath.Join(serverModsDir, fileName)
+
+	// Copy next to the previous version first, which replaceMod then swaps out
+	tmpPath := filepath.Join(serverModsDir, "."+fileName+".download")
+	if err := util.CopyFile(mod.FilePath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy %s: %w", mod.Name, err)
+	}
+
+	mod.FilePath = destPath
+	mod.Enabled = true
+	mod.UpdatedAt = time.Now().Format(time.RFC3339)
+	return replaceMod(mod, tmpPath, getDirManifestPath(serverModsDir))
+}
+
+// DownloadModFileToServer downloads and installs a specific mod file version to a server
+func DownloadModFileToServer(ctx context.Context, modID int, fileID int, serverModsDir string, progressCallback func(progress float64, message string)) error {
+	mod, tmpPath, err := downloadModFileToDir(ctx, modID, fileID, serverModsDir, progressCallback)
+	if err != nil {
+		return err
+	}
+
+	// The installed version is only replaced once the new file is downloaded
+	if err := replaceMod(*mod, tmpPath, getDirManifestPath(serverModsDir)); err != nil {
+		return err
+	}
+
+	if progressCallback != nil {
+		progressCallback(100, fmt.Sprintf("Installed %s v%s on the server", mod.Name, mod.Version))
+	}
+
+	return nil
+}
+
+// PlanServerSync computes the changes that make a server's mods match a set of client mods.
+// Empty modIDs selects every enabled client mod.
+func PlanServerSync(clientMods []Mod, modIDs []string, serverModsDir string) (*SyncPlan, error) {
+	selected := map[string]Mod{}
+	if len(modIDs) == 0 {
+		for _, m := range clientMods {
+			if m.Enabled {
+				selected[m.ID] = m
+			}
+		}
+	} else {
+		byID := map[string]Mod{}
+		for _, m := range clientMods {
+			byID[m.ID] = m
+		}
+		for _, id := range modIDs {
+			m, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("mod not installed on the client: %s", id)
+			}
+			selected[id] = m
+		}
+	}
+
+	serverMods, err := GetServerInstalledMods(serverModsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{
+		Add:       []SyncAction{},
+		Update:    []SyncAction{},
+		Remove:    []SyncAction{},
+		Unchanged: []string{},
+		Unmanaged: []string{},
+		Conflicts: []SyncAction{},
+	}
+
+	managed := map[string]bool{}
+	installed := map[string]Mod{}
+	for _, m := range serverMods {
+		installed[m.ID] = m
+		managed[filepath.Base(m.FilePath)] = true
+	}
+
+	entries, err := os.ReadDir(serverModsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	unmanaged := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || managed[name] || name == "manifest.json" {
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(name)); ext == ".jar" || ext == ".zip" {
+			plan.Unmanaged = append(plan.Unmanaged, name)
+		}
+		unmanaged[name] = true
+	}
+
+	for id, m := range selected {
+		fileName := strings.TrimSuffix(filepath.Base(m.FilePath), ".disabled")
+		existing, ok := installed[id]
+		switch {
+		case unmanaged[fileName]:
+			plan.Conflicts = append(plan.Conflicts, SyncAction{ModID: id, Name: m.Name, FileName: fileName, ToVersion: m.Version})
+		case !ok:
+			plan.Add = append(plan.Add, SyncAction{ModID: id, Name: m.Name, FileName: fileName, ToVersion: m.Version})
+		case !sameModFile(m, existing):
+			plan.Update = append(plan.Update, SyncAction{
+				ModID:       id,
+				Name:        m.Name,
+				FileName:    fileName,
+				FromVersion: existing.Version,
+				ToVersion:   m.Version,
+			})
+		default:
+			plan.Unchanged = append(plan.Unchanged, id)
+		}
+	}
+
+	for id, m := range installed {
+		if _, ok := selected[id]; !ok {
+			plan.Remove = append(plan.Remove, SyncAction{
+				ModID:       id,
+				Name:        m.Name,
+				FileName:    filepath.Base(m.FilePath),
+				FromVersion: m.Version,
+			})
+		}
+	}
+
+	sortActions(plan.Add)
+	sortActions(plan.Update)
+	sortActions(plan.Remove)
+	sortActions(plan.Conflicts)
+	sort.Strings(plan.Unchanged)
+	sort.Strings(plan.Unmanaged)
+	plan.Hash = plan.changesHash()
+
+	return plan, nil
+}
+
+// changesHash hashes the changes of a plan
+func (p *SyncPlan) changesHash() string {
+	data, _ := json.Marshal([][]SyncAction{p.Add, p.Update, p.Remove, p.Conflicts})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ApplyServerSync copies, updates and removes server mods according to a plan
+func ApplyServerSync(plan *SyncPlan, clientMods []Mod, serverModsDir string) error {
+	byID := map[string]Mod{}
+	for _, m := range clientMods {
+		byID[m.ID] = m
+	}
+
+	if err := os.MkdirAll(serverModsDir, 0755); err != nil {
+		return err
+	}
+
+	for _, action := range plan.Remove {
+		if err := RemoveServerMod(action.ModID, serverModsDir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", action.Name, err)
+		}
+	}
+
+	for _, action := range append(append([]SyncAction{}, plan.Add...), plan.Update...) {
+		m, ok := byID[action.ModID]
+		if !ok {
+			return fmt.Errorf("mod not installed on the client: %s", action.ModID)
+		}
+		if err := CopyModToServer(m, serverModsDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameModFile reports whether a server mod is the same file as a client mod
+func sameModFile(client, server Mod) bool {
+	if client.FileID != 0 || server.FileID != 0 {
+		if client.FileID != server.FileID {
+			return false
+		}
+	} else if client.Version != server.Version {
+		return false
+	}
+
+	clientInfo, err := os.Stat(client.FilePath)
+	if err != nil {
+		return true // Nothing to copy; keep what the server has
+	}
+	serverInfo, err := os.Stat(server.FilePath)
+	return err == nil && serverInfo.Size() == clientInfo.Size()
+}
+
+// sortActions orders sync actions by mod name
+func sortActions(actions []SyncAction) {
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Name < actions[j].Name })
+}
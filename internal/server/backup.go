@@ -0,0 +1,413 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/util"
+)
+
+// EventServerBackup is emitted after a world backup was created
+const EventServerBackup = "server-backup"
+
+// Reasons a backup was taken
+const (
+	BackupManual     = "manual"
+	BackupScheduled  = "scheduled"
+	BackupModChange  = "mod-change"
+	BackupPreRestore = "pre-restore"
+)
+
+// Default retention of new servers
+const (
+	DefaultKeepLast   = 5
+	DefaultKeepDaily  = 7
+	DefaultKeepWeekly = 4
+)
+
+const (
+	backupPrefix     = "backup_"
+	backupSuffix     = ".tar.gz"
+	backupTimeLayout = "2006-01-02_15-04-05.000"
+	// legacyBackupTimeLayout is the second resolution layout of backups taken by earlier versions
+	legacyBackupTimeLayout = "2006-01-02_15-04-05"
+	// schedulerInterval is how often scheduled backups are checked
+	schedulerInterval = time.Minute
+)
+
+// worldEntries are the files and directories of a server directory that make up its world data
+var worldEntries = []string{"universe", "config.json", "permissions.json", "whitelist.json", "bans.json"}
+
+// BackupInfo describes a stored world backup
+type BackupInfo struct {
+	ID        string `json:"id"`
+	Server    string `json:"server"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"createdAt"` // RFC 3339
+	Size      int64  `json:"size"`
+	Path      string `json:"path"`
+}
+
+// RetentionPolicy decides which backups are kept
+type RetentionPolicy struct {
+	KeepLast   int // most recent backups
+	KeepDaily  int // newest backup of each of the most recent days
+	KeepWeekly int // newest backup of each of the most recent ISO weeks
+}
+
+// GetBackupsDir returns the directory holding the world backups of a server
+func GetBackupsDir(name string) string {
+	return filepath.Join(env.GetDataDir(), "backups", name)
+}
+
+// retentionPolicy returns the retention policy of a server
+func retentionPolicy(s *Settings) RetentionPolicy {
+	return RetentionPolicy{
+		KeepLast:   s.KeepLastBackups,
+		KeepDaily:  s.KeepDailyBackups,
+		KeepWeekly: s.KeepWeeklyBackups,
+	}
+}
+
+// CreateBackup archives the world data of a server and prunes old backups
+func CreateBackup(name, reason string) (*BackupInfo, error) {
+	return createBackup(name, reason, "")
+}
+
+// createBackup archives the world data of a server and prunes old backups except pinned,
+// e.g. the backup that is about to be restored
+func createBackup(name, reason, pinned string) (*BackupInfo, error) {
+	s, err := LoadSettings(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Two backups within the same millisecond must not overwrite each other
+	createdAt := time.Now()
+	id := backupPrefix + createdAt.Format(backupTimeLayout) + "_" + reason
+	path := filepath.Join(GetBackupsDir(name), id+backupSuffix)
+	for {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		createdAt = createdAt.Add(time.Millisecond)
+		id = backupPrefix + createdAt.Format(backupTimeLayout) + "_" + reason
+		path = filepath.Join(GetBackupsDir(name), id+backupSuffix)
+	}
+
+	fmt.Printf("Backing up world of server %s (%s)...\n", name, reason)
+	if err := util.CreateTarGz(GetServerDir(name), path, worldEntries); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pruneBackups(name, retentionPolicy(s), pinned); err != nil {
+		fmt.Printf("Warning: failed to prune backups of %s: %v\n", name, err)
+	}
+
+	fmt.Printf("Backup %s created (%d bytes)\n", id, info.Size())
+	return &BackupInfo{
+		ID:        id,
+		Server:    name,
+		Reason:    reason,
+		CreatedAt: createdAt.Format(time.RFC3339),
+		Size:      info.Size(),
+		Path:      path,
+	}, nil
+}
+
+// ListBackups returns the backups of a server, newest first
+func ListBackups(name string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(GetBackupsDir(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []BackupInfo{}, nil
+		}
+		return nil, err
+	}
+
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), backupSuffix)
+		if entry.IsDir() || !ok || !strings.HasPrefix(id, backupPrefix) {
+			continue
+		}
+
+		createdAt, reason, ok := parseBackupID(id)
+		if !ok {
+			continue
+		}
+
+		var size int64
+		if info, err := entry.Info(); err == nil {
+			size = info.Size()
+		}
+
+		backups = append(backups, BackupInfo{
+			ID:        id,
+			Server:    name,
+			Reason:    reason,
+			CreatedAt: createdAt.Format(time.RFC3339),
+			Size:      size,
+			Path:      filepath.Join(GetBackupsDir(name), entry.Name()),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID > backups[j].ID })
+	return backups, nil
+}
+
+// parseBackupID extracts the creation time and reason from a backup ID
+func parseBackupID(id string) (time.Time, string, bool) {
+	rest := strings.TrimPrefix(id, backupPrefix)
+	for _, layout := range []string{backupTimeLayout, legacyBackupTimeLayout} {
+		if len(rest) < len(layout)+2 || rest[len(layout)] != '_' {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(layout, rest[:len(layout)], time.Local)
+		if err == nil {
+			return createdAt, rest[len(layout)+1:], true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// PruneBackups deletes the backups of a server that the retention policy does not keep
+func PruneBackups(name string, policy RetentionPolicy) error {
+	return pruneBackups(name, policy, "")
+}
+
+// pruneBackups deletes the backups that the retention policy does not keep, except pinned
+func pruneBackups(name string, policy RetentionPolicy, pinned string) error {
+	backups, err := ListBackups(name)
+	if err != nil {
+		return err
+	}
+
+	keep := SelectRetained(backups, policy)
+	for _, backup := range backups {
+		if keep[backup.ID] || backup.ID == pinned {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return err
+		}
+		fmt.Printf("Pruned backup %s\n", backup.ID)
+	}
+	return nil
+}
+
+// SelectRetained returns the IDs of the backups a policy keeps. backups must be sorted newest first.
+// The newest backup is always kept, whatever the policy.
+func SelectRetained(backups []BackupInfo, policy RetentionPolicy) map[string]bool {
+	keep := map[string]bool{}
+	days := map[string]bool{}
+	weeks := map[string]bool{}
+
+	for i, backup := range backups {
+		if i == 0 || i < policy.KeepLast {
+			keep[backup.ID] = true
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, backup.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		day := createdAt.Format("2006-01-02")
+		if !days[day] && len(days) < policy.KeepDaily {
+			days[day] = true
+			keep[backup.ID] = true
+		}
+
+		year, week := createdAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+		if !weeks[weekKey] && len(weeks) < policy.KeepWeekly {
+			weeks[weekKey] = true
+			keep[backup.ID] = true
+		}
+	}
+
+	return keep
+}
+
+// DeleteBackup removes a single backup
+func DeleteBackup(name, id string) error {
+	path, err := backupPath(name, id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// backupPath validates a backup ID and returns its archive path
+func backupPath(name, id string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(id, backupPrefix) || !isPlainFileName(id) {
+		return "", fmt.Errorf("invalid backup ID: %s", id)
+	}
+
+	path := filepath.Join(GetBackupsDir(name), id+backupSuffix)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("backup %s not found", id)
+	}
+	return path, nil
+}
+
+// restoreBackup replaces the world data of a server with the contents of a backup.
+// The current world is backed up first.
+func restoreBackup(name, id string) error {
+	path, err := backupPath(name, id)
+	if err != nil {
+		return err
+	}
+
+	dir := GetServerDir(name)
+	if pid, found := findJavaProcessInDir(dir); found {
+		if pid > 0 {
+			return fmt.Errorf("a Java process (PID %d) is using %s", pid, dir)
+		}
+		return fmt.Errorf("a Java process is using %s", dir)
+	}
+
+	// The backup being restored must survive the prune that follows the pre-restore backup
+	if _, err := createBackup(name, BackupPreRestore, id); err != nil {
+		return fmt.Errorf("failed to back up current world: %w", err)
+	}
+
+	// Extract next to the world first so that a failed extraction leaves it untouched
+	staging := filepath.Join(dir, ".restore")
+	os.RemoveAll(staging)
+	if err := util.ExtractTarGz(path, staging); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to extract backup: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	for _, entry := range worldEntries {
+		if err := os.RemoveAll(filepath.Join(dir, entry)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry, err)
+		}
+		restored := filepath.Join(staging, entry)
+		if _, err := os.Stat(restored); err != nil {
+			continue
+		}
+		if err := os.Rename(restored, filepath.Join(dir, entry)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry, err)
+		}
+	}
+
+	fmt.Printf("Restored server %s from backup %s\n", name, id)
+	return nil
+}
+
+// isPlainFileName reports whether name is a single path element
+func isPlainFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// Backup creates a world backup of a server and reports it to the listener
+func (m *Manager) Backup(name, reason string) (*BackupInfo, error) {
+	backup, err := CreateBackup(name, reason)
+	if err != nil {
+		return nil, err
+	}
+	m.emit(EventServerBackup, *backup)
+	return backup, nil
+}
+
+// BackupForModChange backs up a server whose mods are about to change, if it asks for it
+func (m *Manager) BackupForModChange(name string) error {
+	s, err := LoadSettings(name)
+	if err != nil || !s.BackupOnModChange {
+		return nil
+	}
+	if _, err := m.Backup(name, BackupModChange); err != nil {
+		return fmt.Errorf("failed to back up server %s: %w", name, err)
+	}
+	return nil
+}
+
+// Restore replaces the world of a server with a backup. It refuses to run while
+// the server or any other Java process is using the server directory.
+func (m *Manager) Restore(name, id string) error {
+	if m.IsRunning(name) {
+		return fmt.Errorf("server %s is running", name)
+	}
+	return restoreBackup(name, id)
+}
+
+// StartBackupScheduler starts taking scheduled backups of every server with a backup interval
+func (m *Manager) StartBackupScheduler() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.schedulerStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	m.schedulerStop = stop
+
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.runScheduledBackups(time.Now())
+			}
+		}
+	}()
+}
+
+// StopBackupScheduler stops taking scheduled backups
+func (m *Manager) StopBackupScheduler() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.schedulerStop != nil {
+		close(m.schedulerStop)
+		m.schedulerStop = nil
+	}
+}
+
+// runScheduledBackups backs up every server whose last backup is older than its interval
+func (m *Manager) runScheduledBackups(now time.Time) {
+	servers, err := List()
+	if err != nil {
+		return
+	}
+
+	for _, s := range servers {
+		if s.BackupIntervalMinutes <= 0 {
+			continue
+		}
+
+		backups, err := ListBackups(s.Name)
+		if err != nil {
+			continue
+		}
+		if len(backups) > 0 {
+			last, err := time.Parse(time.RFC3339, backups[0].CreatedAt)
+			if err == nil && now.Sub(last) < time.Duration(s.BackupIntervalMinutes)*time.Minute {
+				continue
+			}
+		}
+
+		if _, err := m.Backup(s.Name, BackupScheduled); err != nil {
+			fmt.Printf("Warning: scheduled backup of %s failed: %v\n", s.Name, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isJavaName reports whether a process name is a Java runtime
+func isJavaName(name string) bool {
+	return name == "java" || name == "javaw"
+}
+
+// isInside reports whether path is dir or inside it
+func isInside(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+package server
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// findJavaProcessInDir looks for a Java process whose working directory or open files
+// are inside dir. It returns the PID of the first one found.
+func findJavaProcessInDir(dir string) (int, bool) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	if _, err := os.Stat("/proc/self"); err == nil {
+		return findJavaProcessInProc(dir)
+	}
+	return findJavaProcessWithLsof(dir)
+}
+
+// findJavaProcessInProc scans /proc on Linux
+func findJavaProcessInProc(dir string) (int, bool) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, false
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+
+		procDir := filepath.Join("/proc", entry.Name())
+		exe, _ := os.Readlink(filepath.Join(procDir, "exe"))
+		comm, _ := os.ReadFile(filepath.Join(procDir, "comm"))
+		if !isJavaName(filepath.Base(exe)) && !isJavaName(strings.TrimSpace(string(comm))) {
+			continue
+		}
+
+		if cwd, err := os.Readlink(filepath.Join(procDir, "cwd")); err == nil && isInside(cwd, dir) {
+			return pid, true
+		}
+
+		fds, err := os.ReadDir(filepath.Join(procDir, "fd"))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join(procDir, "fd", fd.Name())); err == nil && isInside(target, dir) {
+				return pid, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// findJavaProcessWithLsof asks lsof for the working directories of Java processes on macOS and BSDs
+func findJavaProcessWithLsof(dir string) (int, bool) {
+	out, err := exec.Command("lsof", "-a", "-c", "java", "-d", "cwd", "-Fpn").Output()
+	if err != nil {
+		return 0, false
+	}
+
+	pid := 0
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, "p"):
+			pid, _ = strconv.Atoi(line[1:])
+		case strings.HasPrefix(line, "n") && isInside(line[1:], dir):
+			return pid, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,111 @@
+//go:build windows
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// findJavaProcessInDir looks for a Java process whose executable, working directory or
+// command line is inside dir. It returns the PID of the first one found.
+func findJavaProcessInDir(dir string) (int, bool) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return 0, false
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if int(entry.ProcessID) == os.Getpid() {
+			continue
+		}
+		name := strings.ToLower(windows.UTF16ToString(entry.ExeFile[:]))
+		if !isJavaName(strings.TrimSuffix(name, ".exe")) {
+			continue
+		}
+		if processUsesDir(entry.ProcessID, dir) {
+			return int(entry.ProcessID), true
+		}
+	}
+	return 0, false
+}
+
+// processUsesDir checks the image path, working directory and command line of a process
+func processUsesDir(pid uint32, dir string) bool {
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION|windows.PROCESS_VM_READ, false, pid)
+	if err != nil {
+		// Processes of other users may only allow querying the image path
+		process, err = windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+		if err != nil {
+			return false
+		}
+	}
+	defer windows.CloseHandle(process)
+
+	if isInside(processImagePath(process), dir) {
+		return true
+	}
+
+	cwd, cmdline := processParameters(process)
+	return isInside(cwd, dir) || strings.Contains(strings.ToLower(cmdline), strings.ToLower(dir))
+}
+
+// processImagePath returns the path of the executable of a process
+func processImagePath(process windows.Handle) string {
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(process, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buf[:size])
+}
+
+// processParameters reads the working directory and command line from the process
+// environment block of a process. Both are empty if it cannot be read.
+func processParameters(process windows.Handle) (cwd string, cmdline string) {
+	var info windows.PROCESS_BASIC_INFORMATION
+	if err := windows.NtQueryInformationProcess(process, windows.ProcessBasicInformation, unsafe.Pointer(&info), uint32(unsafe.Sizeof(info)), nil); err != nil {
+		return "", ""
+	}
+
+	var peb windows.PEB
+	if !readProcessMemory(process, uintptr(unsafe.Pointer(info.PebBaseAddress)), unsafe.Pointer(&peb), unsafe.Sizeof(peb)) {
+		return "", ""
+	}
+	var params windows.RTL_USER_PROCESS_PARAMETERS
+	if !readProcessMemory(process, uintptr(unsafe.Pointer(peb.ProcessParameters)), unsafe.Pointer(&params), unsafe.Sizeof(params)) {
+		return "", ""
+	}
+
+	return readProcessString(process, params.CurrentDirectory.DosPath), readProcessString(process, params.CommandLine)
+}
+
+// readProcessString reads a string from the memory of a process
+func readProcessString(process windows.Handle, s windows.NTUnicodeString) string {
+	if s.Length == 0 || s.Buffer == nil {
+		return ""
+	}
+	buf := make([]uint16, s.Length/2)
+	if !readProcessMemory(process, uintptr(unsafe.Pointer(s.Buffer)), unsafe.Pointer(&buf[0]), uintptr(s.Length)) {
+		return ""
+	}
+	return windows.UTF16ToString(buf)
+}
+
+// readProcessMemory copies size bytes at address in a process into dst
+func readProcessMemory(process windows.Handle, address uintptr, dst unsafe.Pointer, size uintptr) bool {
+	var read uintptr
+	err := windows.ReadProcessMemory(process, address, (*byte)(dst), size, &read)
+	return err == nil && read == size
+}
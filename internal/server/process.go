@@ -0,0 +1,342 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"HyPrism/internal/game"
+)
+
+// Server lifecycle and console events emitted to the frontend
+const (
+	EventServerStarted = "server-started"
+	EventServerStopped = "server-stopped"
+	EventServerConsole = "server-console"
+)
+
+const (
+	// stopCommand asks the server to save and shut down
+	stopCommand = "stop"
+	// DefaultStopTimeout is how long a graceful shutdown may take before the server is killed
+	DefaultStopTimeout = 30 * time.Second
+	// consoleBacklog is how many console lines are kept per server
+	consoleBacklog = 500
+)
+
+// ErrServerNotRunning is returned when an operation needs a running server
+var ErrServerNotRunning = errors.New("server is not running")
+
+// ConsoleLine is a line of server console output
+type ConsoleLine struct {
+	Server string `json:"server"`
+	Stream string `json:"stream"` // stdout, stderr or stdin
+	Line   string `json:"line"`
+}
+
+// ProcessInfo describes a run of a server process
+type ProcessInfo struct {
+	Server    string `json:"server"`
+	PID       int    `json:"pid"`
+	StartedAt string `json:"startedAt"` // RFC 3339
+	EndedAt   string `json:"endedAt,omitempty"`
+	Running   bool   `json:"running"`
+	ExitCode  int    `json:"exitCode"`
+	Stopped   bool   `json:"stopped"` // stopped through the launcher
+}
+
+// Listener receives server lifecycle events (ProcessInfo) and console output (ConsoleLine)
+type Listener func(event string, data interface{})
+
+// process is a running server
+type process struct {
+	cmd         *exec.Cmd
+	stdin       io.WriteCloser
+	info        ProcessInfo
+	installPath string
+	stopped     bool
+	done        chan struct{}
+}
+
+// Manager starts and stops local servers. Each server can run at most once.
+// Servers that exit without being stopped are restarted according to their restart policy.
+type Manager struct {
+	mu        sync.Mutex
+	processes map[string]*process
+	restarts  map[string]*time.Timer
+	consoles  map[string][]ConsoleLine
+	listener  Listener
+
+	schedulerStop chan struct{}
+}
+
+// NewManager creates a server manager that reports events to listener
+func NewManager(listener Listener) *Manager {
+	return &Manager{
+		processes: map[string]*process{},
+		restarts:  map[string]*time.Timer{},
+		consoles:  map[string][]ConsoleLine{},
+		listener:  listener,
+	}
+}
+
+// Start starts a server with the bundled JRE of its build
+func (m *Manager) Start(name, installPath string) (ProcessInfo, error) {
+	s, err := LoadSettings(name)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	gameBuild, err := game.ResolveBuild(installPath, s.Patchline, s.Build)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	dir := GetServerDir(name)
+	args, err := buildArgs(s, dir)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p, ok := m.processes[name]; ok {
+		return ProcessInfo{}, fmt.Errorf("server %s is already running (PID %d)", name, p.info.PID)
+	}
+
+	// A manual start replaces a pending restart
+	if timer, ok := m.restarts[name]; ok {
+		timer.Stop()
+		delete(m.restarts, name)
+	}
+
+	cmd := exec.Command(javaPath(gameBuild), args...)
+	cmd.Dir = dir
+	cmd.SysProcAttr = getSysProcAttr()
+	cmd.Stdout = &consoleWriter{onLine: func(line string) { m.appendConsole(name, "stdout", line) }}
+	cmd.Stderr = &consoleWriter{onLine: func(line string) { m.appendConsole(name, "stderr", line) }}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return ProcessInfo{}, fmt.Errorf("failed to open server console: %w", err)
+	}
+
+	fmt.Printf("Starting server %s: %s %s\n", name, cmd.Path, strings.Join(args, " "))
+	if err := cmd.Start(); err != nil {
+		return ProcessInfo{}, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	p := &process{
+		cmd:   cmd,
+		stdin: stdin,
+		info: ProcessInfo{
+			Server:    name,
+			PID:       cmd.Process.Pid,
+			StartedAt: time.Now().Format(time.RFC3339),
+			Running:   true,
+		},
+		installPath: installPath,
+		done:        make(chan struct{}),
+	}
+	m.processes[name] = p
+	m.consoles[name] = nil
+	started := p.info
+
+	go m.wait(name, p)
+
+	fmt.Printf("Server %s started (PID %d)\n", name, started.PID)
+	m.emit(EventServerStarted, started)
+	return started, nil
+}
+
+// wait blocks until a server exits and records how it ended
+func (m *Manager) wait(name string, p *process) {
+	p.cmd.Wait()
+
+	m.mu.Lock()
+	p.info.Running = false
+	p.info.EndedAt = time.Now().Format(time.RFC3339)
+	if state := p.cmd.ProcessState; state != nil {
+		p.info.ExitCode = state.ExitCode()
+	}
+	p.info.Stopped = p.stopped
+	delete(m.processes, name)
+	exited := p.info
+	m.mu.Unlock()
+
+	close(p.done)
+
+	fmt.Printf("Server %s exited (PID %d, code %d)\n", name, exited.PID, exited.ExitCode)
+	m.emit(EventServerStopped, exited)
+
+	// Exit code 0 is a clean shutdown, e.g. the stop command typed into the console
+	if !exited.Stopped && exited.ExitCode != 0 {
+		m.handleCrash(exited, p.installPath, nil)
+	}
+}
+
+// SendCommand writes a console command to a server's stdin
+func (m *Manager) SendCommand(name, command string) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	p, ok := m.processes[name]
+	if ok && strings.TrimPrefix(command, "/") == stopCommand {
+		// A stop typed into the console is not a crash
+		p.stopped = true
+	}
+	m.mu.Unlock()
+	if !ok {
+		return ErrServerNotRunning
+	}
+
+	m.appendConsole(name, "stdin", command)
+	if _, err := io.WriteString(p.stdin, command+"\n"); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+	return nil
+}
+
+// Stop asks a server to shut down and kills it if it has not exited within timeout.
+// A pending restart of a crashed server is cancelled.
+func (m *Manager) Stop(name string, timeout time.Duration) error {
+	m.mu.Lock()
+	p, ok := m.processes[name]
+	if ok {
+		p.stopped = true
+	}
+	m.mu.Unlock()
+	if !ok {
+		if m.cancelRestart(name) {
+			fmt.Printf("Cancelled restart of server %s\n", name)
+			return nil
+		}
+		return ErrServerNotRunning
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultStopTimeout
+	}
+
+	fmt.Printf("Stopping server %s...\n", name)
+	if err := m.SendCommand(name, stopCommand); err != nil {
+		fmt.Printf("Warning: failed to send stop command: %v\n", err)
+	}
+	p.stdin.Close()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	fmt.Printf("Server %s did not stop within %s, killing it\n", name, timeout)
+	if err := p.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill server: %w", err)
+	}
+	<-p.done
+	return nil
+}
+
+// StopAll stops every running server, e.g. when the launcher exits
+func (m *Manager) StopAll(timeout time.Duration) {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.processes))
+	for name := range m.processes {
+		names = append(names, name)
+	}
+	for name, timer := range m.restarts {
+		timer.Stop()
+		delete(m.restarts, name)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := m.Stop(name, timeout); err != nil && !errors.Is(err, ErrServerNotRunning) {
+				fmt.Printf("Warning: failed to stop server %s: %v\n", name, err)
+			}
+		}(name)
+	}
+	wg.Wait()
+}
+
+// Status returns the running process of a server. The second return value is false if it is not running.
+func (m *Manager) Status(name string) (ProcessInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.processes[name]; ok {
+		return p.info, true
+	}
+	return ProcessInfo{}, false
+}
+
+// IsRunning reports whether a server is running
+func (m *Manager) IsRunning(name string) bool {
+	_, ok := m.Status(name)
+	return ok
+}
+
+// Console returns the recent console output of a server
+func (m *Manager) Console(name string) []ConsoleLine {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ConsoleLine{}, m.consoles[name]...)
+}
+
+// appendConsole stores a console line and forwards it to the listener
+func (m *Manager) appendConsole(name, stream, line string) {
+	entry := ConsoleLine{Server: name, Stream: stream, Line: line}
+
+	m.mu.Lock()
+	lines := append(m.consoles[name], entry)
+	if len(lines) > consoleBacklog {
+		lines = lines[len(lines)-consoleBacklog:]
+	}
+	m.consoles[name] = lines
+	m.mu.Unlock()
+
+	m.emit(EventServerConsole, entry)
+}
+
+// emit forwards an event to the listener, if any
+func (m *Manager) emit(event string, data interface{}) {
+	if m.listener != nil {
+		m.listener(event, data)
+	}
+}
+
+// consoleWriter splits process output into lines
+type consoleWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	onLine func(line string)
+}
+
+func (w *consoleWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.buf.Next(i+1)), "\r\n")
+		w.onLine(line)
+	}
+	return len(p), nil
+}
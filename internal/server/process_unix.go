@@ -0,0 +1,10 @@
+//go:build !windows
+
+package server
+
+import "syscall"
+
+// getSysProcAttr returns nil on non-Windows platforms
+func getSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package server
+
+import "syscall"
+
+// createNoWindow keeps the server from opening a console window
+const createNoWindow = 0x08000000
+
+// getSysProcAttr hides the server's console window; its console is shown in the launcher instead
+func getSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: createNoWindow,
+		HideWindow:    true,
+	}
+}
@@ -0,0 +1,318 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"HyPrism/internal/env"
+	"HyPrism/internal/game"
+	"HyPrism/internal/patcher"
+	"HyPrism/internal/util"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// settingsFile holds the launcher's settings for a server directory
+	settingsFile = "hyprism-server.toml"
+	// serverJar is the name the server jar is copied to
+	serverJar = "HytaleServer.jar"
+	// serverAOTCache is the ahead-of-time cache shipped next to the jar, if any
+	serverAOTCache = "HytaleServer.aot"
+	// ModsDirName is the directory the server loads plugins from
+	ModsDirName = "mods"
+)
+
+// Settings configures a local dedicated server
+type Settings struct {
+	Name       string `toml:"name" json:"name"`
+	Patchline  string `toml:"patchline" json:"patchline"`
+	Build      string `toml:"build" json:"build"`
+	AssetsPath string `toml:"assets_path" json:"assetsPath"`
+	MinHeap    string `toml:"min_heap" json:"minHeap"` // e.g. "1G"
+	MaxHeap    string `toml:"max_heap" json:"maxHeap"` // e.g. "4G"
+	JVMArgs    string `toml:"jvm_args" json:"jvmArgs"`
+	ServerArgs string `toml:"server_args" json:"serverArgs"`
+	CreatedAt  string `toml:"created_at" json:"createdAt"`
+
+	// World backups
+	BackupIntervalMinutes int  `toml:"backup_interval_minutes" json:"backupIntervalMinutes"` // 0 disables scheduled backups
+	BackupOnModChange     bool `toml:"backup_on_mod_change" json:"backupOnModChange"`
+	KeepLastBackups       int  `toml:"keep_last_backups" json:"keepLastBackups"`
+	KeepDailyBackups      int  `toml:"keep_daily_backups" json:"keepDailyBackups"`
+	KeepWeeklyBackups     int  `toml:"keep_weekly_backups" json:"keepWeeklyBackups"`
+
+	// Watchdog
+	AutoRestart        bool `toml:"auto_restart" json:"autoRestart"`
+	MaxCrashes         int  `toml:"max_crashes" json:"maxCrashes"`                  // crashes within the window before giving up, 0 for unlimited
+	CrashWindowMinutes int  `toml:"crash_window_minutes" json:"crashWindowMinutes"` // window crashes are counted in
+}
+
+var heapPattern = regexp.MustCompile(`^[0-9]+[KkMmGg]?$`)
+
+// GetServersDir returns the directory holding all local servers
+func GetServersDir() string {
+	return filepath.Join(env.GetDataDir(), "servers")
+}
+
+// GetServerDir returns the directory of a server
+func GetServerDir(name string) string {
+	return filepath.Join(GetServersDir(), name)
+}
+
+// GetModsDir returns the plugin directory of a server
+func GetModsDir(name string) string {
+	return filepath.Join(GetServerDir(name), ModsDirName)
+}
+
+// ValidateName checks that a server name is usable as a directory name
+func ValidateName(name string) error {
+	if name == "" || len(name) > 64 {
+		return fmt.Errorf("server name must be between 1 and 64 characters")
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\:*?"<>|`) {
+		return fmt.Errorf("invalid server name: %s", name)
+	}
+	return nil
+}
+
+// ValidateSettings checks heap sizes and argument quoting
+func ValidateSettings(s *Settings) error {
+	if s.MinHeap != "" && !heapPattern.MatchString(s.MinHeap) {
+		return fmt.Errorf("invalid minimum heap size: %s", s.MinHeap)
+	}
+	if s.MaxHeap != "" && !heapPattern.MatchString(s.MaxHeap) {
+		return fmt.Errorf("invalid maximum heap size: %s", s.MaxHeap)
+	}
+	if _, err := game.SplitCommandLine(s.JVMArgs); err != nil {
+		return fmt.Errorf("invalid JVM arguments: %w", err)
+	}
+	if _, err := game.SplitCommandLine(s.ServerArgs); err != nil {
+		return fmt.Errorf("invalid server arguments: %w", err)
+	}
+	if s.BackupIntervalMinutes < 0 || s.KeepLastBackups < 0 || s.KeepDailyBackups < 0 || s.KeepWeeklyBackups < 0 {
+		return fmt.Errorf("backup interval and retention counts must not be negative")
+	}
+	if s.KeepLastBackups == 0 && s.KeepDailyBackups == 0 && s.KeepWeeklyBackups == 0 {
+		return fmt.Errorf("backup retention must keep at least one backup")
+	}
+	if s.MaxCrashes < 0 {
+		return fmt.Errorf("crash limit must not be negative")
+	}
+	if s.CrashWindowMinutes < 1 {
+		return fmt.Errorf("crash window must be at least one minute")
+	}
+	return nil
+}
+
+// Create creates a standalone server directory with a copy of the server jar of a build
+func Create(name, installPath, patchline, build string) (*Settings, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	dir := GetServerDir(name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("server %s already exists", name)
+	}
+
+	gameBuild, err := game.ResolveBuild(installPath, patchline, build)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Settings{
+		Name:       name,
+		Patchline:  gameBuild.Patchline,
+		Build:      gameBuild.Build,
+		AssetsPath: filepath.Join(gameBuild.GameDir, "Assets.zip"),
+		MinHeap:    "1G",
+		MaxHeap:    "4G",
+		CreatedAt:  time.Now().Format(time.RFC3339),
+
+		BackupOnModChange: true,
+		KeepLastBackups:   DefaultKeepLast,
+		KeepDailyBackups:  DefaultKeepDaily,
+		KeepWeeklyBackups: DefaultKeepWeekly,
+
+		AutoRestart:        true,
+		MaxCrashes:         DefaultMaxCrashes,
+		CrashWindowMinutes: DefaultCrashWindowMinutes,
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ModsDirName), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create server directory: %w", err)
+	}
+
+	if err := copyServerFiles(gameBuild, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	if err := SaveSettings(s); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	fmt.Printf("Created server %s from %s/%s\n", name, s.Patchline, s.Build)
+	return s, nil
+}
+
+// UpdateJar copies the server jar of the server's build again, e.g. after a game update
+func UpdateJar(name, installPath string) error {
+	s, err := LoadSettings(name)
+	if err != nil {
+		return err
+	}
+
+	gameBuild, err := game.ResolveBuild(installPath, s.Patchline, s.Build)
+	if err != nil {
+		return err
+	}
+	return copyServerFiles(gameBuild, GetServerDir(name))
+}
+
+// copyServerFiles copies the server jar and its AOT cache into a server directory
+func copyServerFiles(gameBuild *game.GameBuild, dir string) error {
+	jarPath := patcher.NewClientPatcher("").FindServerPath(gameBuild.GameDir)
+	if jarPath == "" {
+		return fmt.Errorf("server jar not found in %s", gameBuild.GameDir)
+	}
+
+	if err := util.CopyFile(jarPath, filepath.Join(dir, serverJar)); err != nil {
+		return fmt.Errorf("failed to copy server jar: %w", err)
+	}
+
+	aotPath := filepath.Join(filepath.Dir(jarPath), serverAOTCache)
+	if _, err := os.Stat(aotPath); err == nil {
+		if err := util.CopyFile(aotPath, filepath.Join(dir, serverAOTCache)); err != nil {
+			return fmt.Errorf("failed to copy server AOT cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadSettings reads the settings of a server
+func LoadSettings(name string) (*Settings, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(GetServerDir(name), settingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("server %s not found", name)
+		}
+		return nil, err
+	}
+
+	// Settings written before backups and the watchdog existed have no retention or crash keys
+	s := Settings{
+		KeepLastBackups:   DefaultKeepLast,
+		KeepDailyBackups:  DefaultKeepDaily,
+		KeepWeeklyBackups: DefaultKeepWeekly,
+
+		MaxCrashes:         DefaultMaxCrashes,
+		CrashWindowMinutes: DefaultCrashWindowMinutes,
+	}
+	if err := toml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse server settings: %w", err)
+	}
+	s.Name = name
+	return &s, nil
+}
+
+// SaveSettings writes the settings of a server
+func SaveSettings(s *Settings) error {
+	if err := ValidateName(s.Name); err != nil {
+		return err
+	}
+	if err := ValidateSettings(s); err != nil {
+		return err
+	}
+
+	data, err := toml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(GetServerDir(s.Name), settingsFile), data, 0644)
+}
+
+// List returns the settings of every server directory
+func List() ([]Settings, error) {
+	entries, err := os.ReadDir(GetServersDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Settings{}, nil
+		}
+		return nil, err
+	}
+
+	servers := []Settings{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		s, err := LoadSettings(entry.Name())
+		if err != nil {
+			continue
+		}
+		servers = append(servers, *s)
+	}
+
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers, nil
+}
+
+// Delete removes a server directory including its worlds
+func Delete(name string) error {
+	if _, err := LoadSettings(name); err != nil {
+		return err
+	}
+	return os.RemoveAll(GetServerDir(name))
+}
+
+// javaPath returns the java executable of a build's bundled JRE
+func javaPath(gameBuild *game.GameBuild) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(gameBuild.JREDir, "bin", "java.exe")
+	}
+	return filepath.Join(gameBuild.JREDir, "bin", "java")
+}
+
+// buildArgs returns the java arguments that start a server
+func buildArgs(s *Settings, dir string) ([]string, error) {
+	jvmArgs, err := game.SplitCommandLine(s.JVMArgs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JVM arguments: %w", err)
+	}
+	serverArgs, err := game.SplitCommandLine(s.ServerArgs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server arguments: %w", err)
+	}
+
+	var args []string
+	if s.MinHeap != "" {
+		args = append(args, "-Xms"+s.MinHeap)
+	}
+	if s.MaxHeap != "" {
+		args = append(args, "-Xmx"+s.MaxHeap)
+	}
+	if _, err := os.Stat(filepath.Join(dir, serverAOTCache)); err == nil {
+		args = append(args, "-XX:AOTCache="+serverAOTCache)
+	}
+	args = append(args, jvmArgs...)
+	args = append(args, "-jar", serverJar)
+	if s.AssetsPath != "" {
+		args = append(args, "--assets", s.AssetsPath)
+	}
+	args = append(args, serverArgs...)
+
+	return args, nil
+}
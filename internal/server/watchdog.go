@@ -0,0 +1,257 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"HyPrism/internal/util"
+)
+
+// Watchdog events emitted to the frontend
+const (
+	EventServerCrashed    = "server-crashed"
+	EventServerRestarting = "server-restarting"
+)
+
+// Default restart policy of new servers
+const (
+	DefaultMaxCrashes         = 3
+	DefaultCrashWindowMinutes = 10
+)
+
+const (
+	// restartBaseDelay is the delay before the first restart; it doubles with each crash in the window
+	restartBaseDelay = 2 * time.Second
+	// restartMaxDelay caps the restart delay
+	restartMaxDelay = 5 * time.Minute
+	// crashLogTail is how many console lines are kept with a crash
+	crashLogTail = 100
+	// maxCrashHistory is how many crashes are kept per server
+	maxCrashHistory = 50
+
+	crashHistoryFile = "crash-history.json"
+)
+
+// CrashRecord describes an unexpected exit of a server
+type CrashRecord struct {
+	Server       string   `json:"server"`
+	PID          int      `json:"pid"`
+	ExitCode     int      `json:"exitCode"`
+	StartedAt    string   `json:"startedAt"` // RFC 3339
+	EndedAt      string   `json:"endedAt"`   // RFC 3339
+	LogTail      []string `json:"logTail"`
+	RestartDelay string   `json:"restartDelay,omitempty"` // empty if the server was not restarted
+	GaveUp       bool     `json:"gaveUp"`                 // too many crashes within the window
+	Error        string   `json:"error,omitempty"`        // why a restart could not start the server
+}
+
+// RestartPolicy decides whether and when a crashed server is restarted
+type RestartPolicy struct {
+	Enabled    bool
+	MaxCrashes int           // crashes allowed within Window before giving up
+	Window     time.Duration // window crashes are counted in
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// restartPolicy returns the restart policy of a server
+func restartPolicy(s *Settings) RestartPolicy {
+	return RestartPolicy{
+		Enabled:    s.AutoRestart,
+		MaxCrashes: s.MaxCrashes,
+		Window:     time.Duration(s.CrashWindowMinutes) * time.Minute,
+		BaseDelay:  restartBaseDelay,
+		MaxDelay:   restartMaxDelay,
+	}
+}
+
+// NextRestart returns how long to wait before restarting after a crash at now, given earlier
+// crashes. It returns false if the server should not be restarted.
+func (p RestartPolicy) NextRestart(history []CrashRecord, now time.Time) (time.Duration, bool) {
+	if !p.Enabled {
+		return 0, false
+	}
+
+	// Count this crash and every earlier one within the window
+	crashes := 1
+	for _, crash := range history {
+		endedAt, err := time.Parse(time.RFC3339, crash.EndedAt)
+		if err == nil && now.Sub(endedAt) <= p.Window {
+			crashes++
+		}
+	}
+	if p.MaxCrashes > 0 && crashes > p.MaxCrashes {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < crashes && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay, true
+}
+
+// LoadCrashHistory returns the recorded crashes of a server, oldest first
+func LoadCrashHistory(name string) ([]CrashRecord, error) {
+	data, err := os.ReadFile(filepath.Join(GetServerDir(name), crashHistoryFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []CrashRecord{}, nil
+		}
+		return nil, err
+	}
+
+	var history []CrashRecord
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse crash history: %w", err)
+	}
+	return history, nil
+}
+
+// appendCrashHistory records a crash, dropping the oldest entries beyond maxCrashHistory
+func appendCrashHistory(name string, crash CrashRecord) error {
+	history, err := LoadCrashHistory(name)
+	if err != nil {
+		history = []CrashRecord{}
+	}
+
+	history = append(history, crash)
+	if len(history) > maxCrashHistory {
+		history = history[len(history)-maxCrashHistory:]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(filepath.Join(GetServerDir(name), crashHistoryFile), data, 0644)
+}
+
+// CrashHistoryFiles returns the crash history files of all servers
+func CrashHistoryFiles() []string {
+	paths, _ := filepath.Glob(filepath.Join(GetServersDir(), "*", crashHistoryFile))
+	return paths
+}
+
+// ClearCrashHistory removes the recorded crashes of a server
+func ClearCrashHistory(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(GetServerDir(name), crashHistoryFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// handleCrash records an unexpected exit, or a restart that failed with startErr, and
+// schedules a restart if the policy allows it
+func (m *Manager) handleCrash(exited ProcessInfo, installPath string, startErr error) {
+	name := exited.Server
+
+	var tail []string
+	for _, line := range m.Console(name) {
+		if line.Stream != "stdin" {
+			tail = append(tail, line.Line)
+		}
+	}
+	if len(tail) > crashLogTail {
+		tail = tail[len(tail)-crashLogTail:]
+	}
+
+	crash := CrashRecord{
+		Server:    name,
+		PID:       exited.PID,
+		ExitCode:  exited.ExitCode,
+		StartedAt: exited.StartedAt,
+		EndedAt:   exited.EndedAt,
+		LogTail:   tail,
+	}
+	if startErr != nil {
+		crash.Error = startErr.Error()
+	}
+
+	var delay time.Duration
+	restart := false
+	if s, err := LoadSettings(name); err == nil {
+		history, _ := LoadCrashHistory(name)
+		policy := restartPolicy(s)
+		delay, restart = policy.NextRestart(history, time.Now())
+		crash.GaveUp = policy.Enabled && !restart
+	}
+	if restart {
+		crash.RestartDelay = delay.String()
+	}
+
+	if err := appendCrashHistory(name, crash); err != nil {
+		fmt.Printf("Warning: failed to record crash of server %s: %v\n", name, err)
+	}
+
+	if startErr != nil {
+		fmt.Printf("Restart of server %s failed: %v\n", name, startErr)
+	} else {
+		fmt.Printf("Server %s crashed (code %d)\n", name, crash.ExitCode)
+	}
+	m.emit(EventServerCrashed, crash)
+
+	if crash.GaveUp {
+		fmt.Printf("Server %s crashed too often, not restarting\n", name)
+		return
+	}
+	if !restart {
+		return
+	}
+
+	fmt.Printf("Restarting server %s in %s\n", name, delay)
+	m.emit(EventServerRestarting, map[string]interface{}{
+		"server": name,
+		"delay":  delay.String(),
+	})
+
+	m.mu.Lock()
+	m.restarts[name] = time.AfterFunc(delay, func() {
+		m.mu.Lock()
+		_, pending := m.restarts[name]
+		delete(m.restarts, name)
+		m.mu.Unlock()
+		if !pending {
+			return
+		}
+
+		// A failed start counts as a crash, so it is retried within the same limits
+		if _, err := m.Start(name, installPath); err != nil && !m.IsRunning(name) {
+			now := time.Now().Format(time.RFC3339)
+			m.handleCrash(ProcessInfo{Server: name, StartedAt: now, EndedAt: now, ExitCode: -1}, installPath, err)
+		}
+	})
+	m.mu.Unlock()
+}
+
+// cancelRestart cancels a pending restart. It returns false if none was pending.
+func (m *Manager) cancelRestart(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timer, ok := m.restarts[name]
+	if !ok {
+		return false
+	}
+	timer.Stop()
+	delete(m.restarts, name)
+	return true
+}
+
+// IsRestartPending reports whether a crashed server is waiting to be restarted
+func (m *Manager) IsRestartPending(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.restarts[name]
+	return ok
+}
@@ -0,0 +1,182 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// BackupSuffix is appended to a file name for its last known good copy
+	BackupSuffix = ".bak"
+	// CorruptSuffix is appended to a file name for a corrupted copy that was replaced
+	CorruptSuffix = ".corrupt"
+)
+
+// RecoveryNotice describes a corrupted or missing file that was detected on load
+type RecoveryNotice struct {
+	Path      string `json:"path"`
+	Problem   string `json:"problem"`
+	Recovered bool   `json:"recovered"` // restored from its last known good copy
+}
+
+var (
+	recoveryMu       sync.Mutex
+	recoveryNotices  []RecoveryNotice
+	recoveryListener func(RecoveryNotice)
+)
+
+// SetRecoveryListener sets the function told about recovered files, e.g. to warn the user.
+// Notices from before the listener was set are delivered right away.
+func SetRecoveryListener(listener func(RecoveryNotice)) {
+	recoveryMu.Lock()
+	pending := recoveryNotices
+	recoveryNotices = nil
+	recoveryListener = listener
+	recoveryMu.Unlock()
+
+	for _, notice := range pending {
+		listener(notice)
+	}
+}
+
+// notifyRecovery logs a recovery notice and passes it on
+func notifyRecovery(notice RecoveryNotice) {
+	if notice.Recovered {
+		fmt.Printf("Warning: %s was damaged (%s) and has been restored from its backup\n", notice.Path, notice.Problem)
+	} else {
+		fmt.Printf("Warning: %s is damaged (%s) and no usable backup exists, it was moved to %s\n", notice.Path, notice.Problem, notice.Path+CorruptSuffix)
+	}
+
+	recoveryMu.Lock()
+	listener := recoveryListener
+	if listener == nil {
+		recoveryNotices = append(recoveryNotices, notice)
+	}
+	recoveryMu.Unlock()
+
+	if listener != nil {
+		listener(notice)
+	}
+}
+
+// WriteFileAtomic replaces path with data. The data is written to a temporary file, synced
+// and renamed over path, so a crash or full disk never leaves a truncated file behind.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(path, data, perm)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// WriteFileWithBackup replaces path with data like WriteFileAtomic and keeps the previous
+// content as the last known good copy, which ReadFileWithBackup falls back to
+func WriteFileWithBackup(path string, data []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(path, data, perm)
+	if err != nil {
+		return err
+	}
+
+	// If a crash happens between the renames, path is missing and the backup is used
+	if err := os.Rename(path, path+BackupSuffix); err != nil && !os.IsNotExist(err) {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// ReadFileWithBackup reads path and checks it with valid. If path is missing or fails the check,
+// its last known good copy is restored and used instead. A damaged file without a usable
+// copy is moved aside so that it is not overwritten and an error is returned.
+func ReadFileWithBackup(path string, valid func([]byte) error) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err = valid(data); err == nil {
+			return data, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	problem := err
+	exists := !os.IsNotExist(problem)
+
+	backup, err := os.ReadFile(path + BackupSuffix)
+	if err == nil {
+		err = valid(backup)
+	}
+	if err != nil {
+		if !exists {
+			return nil, problem
+		}
+		os.Rename(path, path+CorruptSuffix)
+		notifyRecovery(RecoveryNotice{Path: path, Problem: problem.Error()})
+		return nil, fmt.Errorf("%s is damaged and has no usable backup: %w", filepath.Base(path), problem)
+	}
+
+	if exists {
+		os.Rename(path, path+CorruptSuffix)
+	}
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path + BackupSuffix); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := WriteFileAtomic(path, backup, perm); err != nil {
+		return nil, fmt.Errorf("failed to restore %s from its backup: %w", filepath.Base(path), err)
+	}
+
+	if !exists {
+		problem = fmt.Errorf("file was missing")
+	}
+	notifyRecovery(RecoveryNotice{Path: path, Problem: problem.Error(), Recovered: true})
+	return backup, nil
+}
+
+// RemoveBackup deletes the last known good copy of path, e.g. when it holds data that was deleted on purpose
+func RemoveBackup(path string) error {
+	err := os.Remove(path + BackupSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeTemp writes data to a synced temporary file next to path and returns its name
+func writeTemp(path string, data []byte, perm os.FileMode) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmp := file.Name()
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
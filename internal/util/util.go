@@ -148,6 +148,160 @@ func ExtractTarGz(src, dest string) error {
 	return nil
 }
 
+// CreateZip creates a ZIP archive at dest containing the contents of the src directory
+func CreateZip(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	outFile, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	writer := zip.NewWriter(outFile)
+
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			header.Name += "/"
+			_, err := writer.CreateHeader(header)
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		header.Method = zip.Deflate
+
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(w, file)
+		return err
+	})
+	if err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+// CreateTarGz writes the given entries of src (files or directories, relative to src)
+// to a gzip-compressed tar archive at dest. Missing entries are skipped. Files may be
+// written to while they are archived, e.g. by a running server: each file is read in
+// full before its header is written, and files deleted meanwhile are skipped.
+func CreateTarGz(src, dest string, entries []string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file so that an interrupted backup never looks complete
+	tmp := dest + ".tmp"
+	outFile, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	gzWriter := gzip.NewWriter(outFile)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	writeEntry := func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		// The size in the header must match the data, which may have changed since the walk
+		var data []byte
+		if !info.IsDir() {
+			data, err = os.ReadFile(path)
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Size = int64(len(data))
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = tarWriter.Write(data)
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(src, entry)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err = filepath.Walk(path, writeEntry); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = tarWriter.Close()
+	}
+	if err == nil {
+		err = gzWriter.Close()
+	}
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dest)
+}
+
 // ExtractArchive extracts an archive based on its extension
 func ExtractArchive(src, dest string) error {
 	if strings.HasSuffix(src, ".zip") {
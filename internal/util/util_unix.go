@@ -2,9 +2,20 @@
 
 package util
 
-import "os/exec"
+import (
+	"os"
+	"os/exec"
+)
 
 // HideConsoleWindow is a no-op on non-Windows platforms
 func HideConsoleWindow(cmd *exec.Cmd) {
 	// No-op on Unix systems
 }
+
+// syncDir flushes a directory so that a rename inside it survives a crash
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -14,3 +14,6 @@ func HideConsoleWindow(cmd *exec.Cmd) {
 		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
 	}
 }
+
+// syncDir is a no-op on Windows, where renames are journaled by NTFS and directories cannot be synced
+func syncDir(dir string) {}
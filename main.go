@@ -2,6 +2,7 @@ package main
 
 import (
 	"HyPrism/app"
+	"HyPrism/internal/env"
 	"embed"
 	"os"
 	"path/filepath"
@@ -18,12 +19,26 @@ import (
 var assets embed.FS
 
 func main() {
+	// Get executable directory for portable mode and portable WebView2
+	exePath, _ := os.Executable()
+	exeDir := filepath.Dir(exePath)
+
+	// Portable mode keeps all state next to the executable, so it must be known before any path is used
+	env.DetectPortable(exeDir, os.Args[1:])
+
+	// Move data of older versions out of ~/.config into the XDG data, cache and state directories
+	if err := env.MigrateLegacyLayout(); err != nil {
+		println("Warning:", err.Error())
+	}
+
+	// Command line modes (dry run, launch scripts, headless launch) run without the window
+	if handled, exitCode := app.RunCLI(os.Args[1:]); handled {
+		os.Exit(exitCode)
+	}
+
 	// Create an instance of the app structure
 	application := app.NewApp()
 
-	// Get executable directory for portable WebView2
-	exePath, _ := os.Executable()
-	exeDir := filepath.Dir(exePath)
 	webviewDir := filepath.Join(exeDir, "WebView2")
 
 	err := wails.Run(&options.App{